@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// recordExpense writes amount into both the daily-total cell and category
+// cell for sheetName, batching the two current-value reads into a single
+// batchGet and the two writes into a single batchUpdate — two Sheets calls
+// per /add instead of the five separate Get/Update round trips a naive
+// read-then-write per cell would need.
+func (sc *SheetsClient) recordExpense(ctx context.Context, config Config, sheetName, category string, amount float64, isCard bool, lang string, messages Messages, rowCache *categoryRowCache) (ExpenseRecord, error) {
+	rowIndex, found, err := rowCache.rowFor(ctx, sc, config, sheetName, category)
+	if err != nil {
+		return ExpenseRecord{}, err
+	}
+	if !found {
+		return ExpenseRecord{}, fmt.Errorf(messages[lang]["category_not_found"], category)
+	}
+
+	dailyCell := sc.dailyCellAddress(sheetName)
+	categoryCell := sc.categoryCellAddress(config, sheetName, rowIndex)
+
+	current, err := sc.batchGetFormulas(ctx, config.SpreadsheetID, []string{dailyCell, categoryCell})
+	if err != nil {
+		return ExpenseRecord{}, fmt.Errorf("failed to read current cell values: %w", err)
+	}
+
+	formattedAmount := sc.formatAmount(amount, isCard)
+	updates := map[string]string{
+		dailyCell:    sc.buildNewFormula(current[dailyCell], formattedAmount),
+		categoryCell: sc.buildNewFormula(current[categoryCell], formattedAmount),
+	}
+
+	if err := sc.batchUpdateCells(ctx, config.SpreadsheetID, updates); err != nil {
+		return ExpenseRecord{}, fmt.Errorf("failed to write expense cells: %w", err)
+	}
+
+	return ExpenseRecord{
+		SheetName:     sheetName,
+		DailyCell:     dailyCell,
+		CategoryCell:  categoryCell,
+		FormattedTerm: formattedAmount,
+	}, nil
+}
+
+// recordReceipt splits a scanned receipt's line items across their category
+// cells plus the shared daily-total cell, reading every distinct cell's
+// current formula in one batchGet and writing all of them back in one
+// batchUpdate regardless of how many categories the receipt touches.
+func (sc *SheetsClient) recordReceipt(ctx context.Context, config Config, sheetName string, items []ReceiptLineItem, isCard bool, lang string, messages Messages, rowCache *categoryRowCache) ([]ExpenseRecord, error) {
+	dailyCell := sc.dailyCellAddress(sheetName)
+
+	categoryCells := make([]string, len(items))
+	cellSet := map[string]struct{}{dailyCell: {}}
+	for i, item := range items {
+		rowIndex, found, err := rowCache.rowFor(ctx, sc, config, sheetName, item.Category)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf(messages[lang]["category_not_found"], item.Category)
+		}
+		categoryCells[i] = sc.categoryCellAddress(config, sheetName, rowIndex)
+		cellSet[categoryCells[i]] = struct{}{}
+	}
+
+	cells := make([]string, 0, len(cellSet))
+	for cell := range cellSet {
+		cells = append(cells, cell)
+	}
+
+	current, err := sc.batchGetFormulas(ctx, config.SpreadsheetID, cells)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read current cell values: %w", err)
+	}
+
+	// Track running totals per cell locally, without a leading '=', so
+	// multiple line items landing on the same category cell (or the shared
+	// daily cell) each append their own term instead of clobbering one
+	// another or re-prefixing an already-built formula with another '='.
+	running := make(map[string]string, len(cells))
+	for cell, value := range current {
+		running[cell] = value
+	}
+
+	records := make([]ExpenseRecord, len(items))
+	for i, item := range items {
+		formattedAmount := sc.formatAmount(item.Amount, isCard)
+
+		running[dailyCell] = appendFormulaTerm(running[dailyCell], formattedAmount)
+		running[categoryCells[i]] = appendFormulaTerm(running[categoryCells[i]], formattedAmount)
+
+		records[i] = ExpenseRecord{
+			SheetName:     sheetName,
+			DailyCell:     dailyCell,
+			CategoryCell:  categoryCells[i],
+			FormattedTerm: formattedAmount,
+		}
+	}
+
+	updates := make(map[string]string, len(running))
+	for cell, value := range running {
+		updates[cell] = fmt.Sprintf("=%s", value)
+	}
+
+	if err := sc.batchUpdateCells(ctx, config.SpreadsheetID, updates); err != nil {
+		return nil, fmt.Errorf("failed to write receipt cells: %w", err)
+	}
+
+	return records, nil
+}
+
+// appendFormulaTerm appends term to a '='-stripped sum formula, matching
+// what batchGetFormulas returns and buildNewFormula expects before it adds
+// the leading '=' back at write time.
+func appendFormulaTerm(current, term string) string {
+	if current == "" {
+		return term
+	}
+	return current + "+" + term
+}
+
+func (sc *SheetsClient) dailyCellAddress(sheetName string) string {
+	row := time.Now().Day() + 1
+	return fmt.Sprintf("%s!I%d", sheetName, row)
+}
+
+func (sc *SheetsClient) categoryCellAddress(config Config, sheetName string, rowIndex int) string {
+	row := 22 + rowIndex
+	return fmt.Sprintf("%s!%s%d", sheetName, config.CellRanges.CategoryColumn, row)
+}
+
+// batchGetFormulas reads the current formula (with any leading '=' stripped,
+// matching getCellValue) for every cell in one batchGet call. A cell with no
+// existing value is reported as "".
+func (sc *SheetsClient) batchGetFormulas(ctx context.Context, spreadsheetID string, cells []string) (map[string]string, error) {
+	var resp *sheets.BatchGetValuesResponse
+	err := withRetry(ctx, func() error {
+		var err error
+		resp, err = sc.service.Spreadsheets.Values.BatchGet(spreadsheetID).
+			Ranges(cells...).
+			ValueRenderOption("FORMULA").
+			Context(ctx).
+			Do()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to batch get cells: %w", err)
+	}
+
+	values := make(map[string]string, len(cells))
+	for i, cell := range cells {
+		values[cell] = ""
+		if i >= len(resp.ValueRanges) {
+			continue
+		}
+		vr := resp.ValueRanges[i]
+		if len(vr.Values) == 0 || len(vr.Values[0]) == 0 {
+			continue
+		}
+		values[cell] = strings.TrimPrefix(fmt.Sprintf("%v", vr.Values[0][0]), "=")
+	}
+	return values, nil
+}
+
+// batchUpdateCells writes every cell->value pair in updates in a single
+// batchUpdate call.
+func (sc *SheetsClient) batchUpdateCells(ctx context.Context, spreadsheetID string, updates map[string]string) error {
+	data := make([]*sheets.ValueRange, 0, len(updates))
+	for cell, value := range updates {
+		data = append(data, &sheets.ValueRange{
+			Range:  cell,
+			Values: [][]interface{}{{value}},
+		})
+	}
+
+	req := &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "USER_ENTERED",
+		Data:             data,
+	}
+
+	return withRetry(ctx, func() error {
+		_, err := sc.service.Spreadsheets.Values.BatchUpdate(spreadsheetID, req).Context(ctx).Do()
+		return err
+	})
+}
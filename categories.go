@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// listCategories returns the category labels configured in CategoryRange
+// for sheetName, in sheet order.
+func (sc *SheetsClient) listCategories(ctx context.Context, config Config, sheetName string) ([]string, error) {
+	categoryRange := fmt.Sprintf("'%s'!%s", sheetName, config.CellRanges.CategoryRange)
+	var resp *sheets.ValueRange
+	err := withRetry(ctx, func() error {
+		var err error
+		resp, err = sc.service.Spreadsheets.Values.Get(config.SpreadsheetID, categoryRange).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category range: %w", err)
+	}
+
+	categories := make([]string, 0, len(resp.Values))
+	for _, row := range resp.Values {
+		if len(row) > 0 {
+			categories = append(categories, fmt.Sprintf("%v", row[0]))
+		}
+	}
+	return categories, nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+)
+
+// Embedder turns text into a dense vector, used to map a free-form category
+// guess onto the nearest row that actually exists in CategoryRange.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float64, error)
+}
+
+// embeddingCache avoids re-embedding the same category text (and the same
+// sheet rows) on every message.
+type embeddingCache struct {
+	mu     sync.Mutex
+	vector map[string][]float64
+}
+
+func newEmbeddingCache() *embeddingCache {
+	return &embeddingCache{vector: make(map[string][]float64)}
+}
+
+func (c *embeddingCache) get(ctx context.Context, embedder Embedder, text string) ([]float64, error) {
+	key := strings.ToLower(strings.TrimSpace(text))
+
+	c.mu.Lock()
+	if v, ok := c.vector[key]; ok {
+		c.mu.Unlock()
+		return v, nil
+	}
+	c.mu.Unlock()
+
+	v, err := embedder.Embed(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.vector[key] = v
+	c.mu.Unlock()
+	return v, nil
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// nearestCategory embeds guess and every candidate, returning the candidate
+// with the highest cosine similarity. Candidates are the rows already
+// present in CategoryRange, so the result is always a category Sheets knows
+// how to write to.
+func nearestCategory(ctx context.Context, embedder Embedder, cache *embeddingCache, guess string, candidates []string) (string, float64, error) {
+	if len(candidates) == 0 {
+		return "", 0, fmt.Errorf("no candidate categories to match against")
+	}
+
+	guessVector, err := cache.get(ctx, embedder, guess)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to embed category guess %q: %w", guess, err)
+	}
+
+	var best string
+	var bestScore float64 = -1
+	for _, candidate := range candidates {
+		candidateVector, err := cache.get(ctx, embedder, candidate)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to embed candidate category %q: %w", candidate, err)
+		}
+		if score := cosineSimilarity(guessVector, candidateVector); score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	return best, bestScore, nil
+}
+
+// nearestCategoryByKeyword is the no-embeddings fallback for resolveCategory:
+// it scores each candidate by the fraction of whitespace-split words it
+// shares with guess and returns the best-scoring one. Used when no Embedder
+// is configured (e.g. the zero-config tesseract receipt path), so a
+// category guess still has a chance of landing on a real CategoryRange row
+// instead of being passed through verbatim.
+func nearestCategoryByKeyword(guess string, candidates []string) (string, bool) {
+	guessWords := categoryWords(guess)
+	if len(guessWords) == 0 {
+		return "", false
+	}
+
+	var best string
+	var bestScore float64
+	for _, candidate := range candidates {
+		candidateWords := categoryWords(candidate)
+		if len(candidateWords) == 0 {
+			continue
+		}
+
+		var shared int
+		for word := range guessWords {
+			if candidateWords[word] {
+				shared++
+			}
+		}
+		score := float64(shared) / float64(len(guessWords)+len(candidateWords)-shared)
+		if score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	return best, bestScore > 0
+}
+
+func categoryWords(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		words[word] = true
+	}
+	return words
+}
+
+// openAIEmbedder embeds text via OpenAI's embeddings endpoint.
+type openAIEmbedder struct {
+	apiKey  string
+	model   string
+	baseURL string
+}
+
+func newOpenAIEmbedder(cfg ParserConfig) *openAIEmbedder {
+	return &openAIEmbedder{
+		apiKey:  cfg.APIKey,
+		model:   defaultString(cfg.EmbeddingModel, "text-embedding-3-small"),
+		baseURL: defaultString(cfg.BaseURL, "https://api.openai.com/v1"),
+	}
+}
+
+func (e *openAIEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	body := map[string]interface{}{
+		"model": e.model,
+		"input": text,
+	}
+
+	var resp struct {
+		Data []struct {
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := postJSON(ctx, e.baseURL+"/embeddings", e.apiKey, body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("openai returned no embedding for %q", text)
+	}
+	return resp.Data[0].Embedding, nil
+}
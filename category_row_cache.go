@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// categoryRowTTL bounds how long a cached category->row mapping is trusted
+// before recordExpense re-fetches CategoryRange from Sheets. Category rows
+// rarely move within a month, so this is deliberately generous.
+const categoryRowTTL = 10 * time.Minute
+
+// categoryRowCache remembers which sheet row each category lives on, so a
+// batched expense write only needs one round trip per cell instead of one
+// extra Get to re-resolve the category's row on every /add.
+type categoryRowCache struct {
+	mu      sync.Mutex
+	entries map[string]categoryRowEntry
+}
+
+type categoryRowEntry struct {
+	rows      map[string]int
+	expiresAt time.Time
+}
+
+func newCategoryRowCache() *categoryRowCache {
+	return &categoryRowCache{entries: make(map[string]categoryRowEntry)}
+}
+
+// rowFor returns category's zero-based row within CategoryRange for
+// spreadsheetID/sheetName, refreshing the cached mapping on a miss or
+// expiry. found is false if category isn't in the sheet's category list.
+func (c *categoryRowCache) rowFor(ctx context.Context, sc *SheetsClient, config Config, sheetName, category string) (row int, found bool, err error) {
+	key := config.SpreadsheetID + "|" + sheetName
+
+	if row, found := c.lookup(key, category); found {
+		return row, true, nil
+	}
+
+	if err := c.refresh(ctx, sc, config, sheetName, key); err != nil {
+		return 0, false, err
+	}
+
+	row, found = c.lookup(key, category)
+	return row, found, nil
+}
+
+func (c *categoryRowCache) lookup(key, category string) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return 0, false
+	}
+	row, found := entry.rows[normalizeCategoryKey(category)]
+	return row, found
+}
+
+func (c *categoryRowCache) refresh(ctx context.Context, sc *SheetsClient, config Config, sheetName, key string) error {
+	categories, err := sc.listCategories(ctx, config, sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to refresh category rows: %w", err)
+	}
+
+	rows := make(map[string]int, len(categories))
+	for i, category := range categories {
+		rows[normalizeCategoryKey(category)] = i
+	}
+
+	c.mu.Lock()
+	c.entries[key] = categoryRowEntry{rows: rows, expiresAt: time.Now().Add(categoryRowTTL)}
+	c.mu.Unlock()
+	return nil
+}
+
+func normalizeCategoryKey(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// splitFormulaTerms splits a "=a+b+c" sum formula (already stripped of its
+// leading '=' by getCellValue) into its individual terms.
+func splitFormulaTerms(formula string) []string {
+	if formula == "" {
+		return nil
+	}
+	return strings.Split(formula, "+")
+}
+
+// joinFormulaTerms rebuilds a "=a+b+c" formula from its terms, or "" if none remain.
+func joinFormulaTerms(terms []string) string {
+	if len(terms) == 0 {
+		return ""
+	}
+	return strings.Join(terms, "+")
+}
+
+// removeFormulaTerm removes the first term equal to target from currentValue
+// (an already '='-stripped sum formula) and returns the resulting formula,
+// again without its leading '='. It errors if target is not present, so
+// callers can tell a stale /undo from a successful one.
+func (sc *SheetsClient) removeFormulaTerm(currentValue, target string) (string, error) {
+	terms := splitFormulaTerms(currentValue)
+	for i, term := range terms {
+		if term == target {
+			terms = append(terms[:i], terms[i+1:]...)
+			return joinFormulaTerms(terms), nil
+		}
+	}
+	return "", fmt.Errorf("term %q not found in formula %q", target, currentValue)
+}
+
+// replaceFormulaTerm swaps the first term equal to oldTerm for newTerm in
+// currentValue (an already '='-stripped sum formula).
+func (sc *SheetsClient) replaceFormulaTerm(currentValue, oldTerm, newTerm string) (string, error) {
+	terms := splitFormulaTerms(currentValue)
+	for i, term := range terms {
+		if term == oldTerm {
+			terms[i] = newTerm
+			return joinFormulaTerms(terms), nil
+		}
+	}
+	return "", fmt.Errorf("term %q not found in formula %q", oldTerm, currentValue)
+}
@@ -0,0 +1,76 @@
+package main
+
+import "testing"
+
+func TestRemoveFormulaTerm(t *testing.T) {
+	sc := &SheetsClient{}
+
+	tests := []struct {
+		name    string
+		current string
+		target  string
+		want    string
+		wantErr bool
+	}{
+		{name: "only term", current: "5", target: "5", want: ""},
+		{name: "first of many", current: "5+10+15", target: "5", want: "10+15"},
+		{name: "middle of many", current: "5+10+15", target: "10", want: "5+15"},
+		{name: "last of many", current: "5+10+15", target: "15", want: "5+10"},
+		{name: "not present", current: "5+10", target: "99", wantErr: true},
+		{name: "empty formula", current: "", target: "5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sc.removeFormulaTerm(tt.current, tt.target)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("removeFormulaTerm(%q, %q) = %q, want error", tt.current, tt.target, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("removeFormulaTerm(%q, %q) returned unexpected error: %v", tt.current, tt.target, err)
+			}
+			if got != tt.want {
+				t.Errorf("removeFormulaTerm(%q, %q) = %q, want %q", tt.current, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplaceFormulaTerm(t *testing.T) {
+	sc := &SheetsClient{}
+
+	tests := []struct {
+		name    string
+		current string
+		oldTerm string
+		newTerm string
+		want    string
+		wantErr bool
+	}{
+		{name: "only term", current: "5", oldTerm: "5", newTerm: "7.5", want: "7.5"},
+		{name: "middle of many", current: "5+10+15", oldTerm: "10", newTerm: "20", want: "5+20+15"},
+		{name: "duplicate terms replaces first", current: "5+5+15", oldTerm: "5", newTerm: "8", want: "8+5+15"},
+		{name: "not present", current: "5+10", oldTerm: "99", newTerm: "1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sc.replaceFormulaTerm(tt.current, tt.oldTerm, tt.newTerm)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("replaceFormulaTerm(%q, %q, %q) = %q, want error", tt.current, tt.oldTerm, tt.newTerm, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("replaceFormulaTerm(%q, %q, %q) returned unexpected error: %v", tt.current, tt.oldTerm, tt.newTerm, err)
+			}
+			if got != tt.want {
+				t.Errorf("replaceFormulaTerm(%q, %q, %q) = %q, want %q", tt.current, tt.oldTerm, tt.newTerm, got, tt.want)
+			}
+		})
+	}
+}
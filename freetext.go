@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// pendingExpense is a parsed expense awaiting the user's inline-keyboard
+// confirmation because the Parser wasn't confident enough to write it
+// straight to Sheets.
+type pendingExpense struct {
+	message  *tgbotapi.Message
+	lang     string
+	amount   float64
+	category string
+	isCard   bool
+}
+
+// pendingExpenseStore holds each user's awaited confirmation. Updates and
+// callback queries are both dispatched onto their own goroutine (see
+// Bot.Start), so access must be synchronized like every other per-user
+// session store in this file's family (categoryRowCache, FSMStore, receiptSessions).
+type pendingExpenseStore struct {
+	mu      sync.Mutex
+	pending map[int64]pendingExpense
+}
+
+func newPendingExpenseStore() *pendingExpenseStore {
+	return &pendingExpenseStore{pending: make(map[int64]pendingExpense)}
+}
+
+func (s *pendingExpenseStore) set(userID int64, pending pendingExpense) {
+	s.mu.Lock()
+	s.pending[userID] = pending
+	s.mu.Unlock()
+}
+
+func (s *pendingExpenseStore) getAndDelete(userID int64) (pendingExpense, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending, ok := s.pending[userID]
+	if ok {
+		delete(s.pending, userID)
+	}
+	return pending, ok
+}
+
+// handleFreeText is the non-command path: it lets users type e.g. "spent
+// 12.50 on coffee with card" instead of the strict /add grammar.
+func (b *Bot) handleFreeText(ctx context.Context, message *tgbotapi.Message, lang string) {
+	parsed, err := b.parser.Parse(ctx, message.Text)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["parse_failed"])
+		return
+	}
+
+	category := b.resolveCategory(ctx, message.From.ID, lang, parsed.Category)
+	isCard := parsed.PaymentMethod == "card"
+
+	if parsed.PaymentMethod == "" || parsed.Confidence < confidenceThreshold {
+		b.askExpenseConfirmation(message, lang, parsed.Amount, category, isCard)
+		return
+	}
+
+	b.finalizeExpense(ctx, message.Chat.ID, message.From.ID, lang, parsed.Amount, category, isCard)
+}
+
+// resolveCategory maps a free-form category guess onto the nearest row that
+// actually exists in CategoryRange. It prefers the configured parser's
+// embeddings backend when there is one, and otherwise falls back to a
+// keyword-overlap match so guesses coming from providers with no embeddings
+// backend configured — e.g. the zero-config tesseract receipt extractor —
+// still have a chance of landing on a real row instead of being passed
+// through verbatim.
+func (b *Bot) resolveCategory(ctx context.Context, userID int64, lang, guess string) string {
+	if guess == "" {
+		return guess
+	}
+
+	sheetsClient, userConfig, err := b.resolveSheetsClient(ctx, userID)
+	if err != nil {
+		return guess
+	}
+
+	sheetName := b.getSheetName(lang)
+	candidates, err := sheetsClient.listCategories(ctx, userConfig, sheetName)
+	if err != nil || len(candidates) == 0 {
+		return guess
+	}
+
+	if b.categoryEmbedder != nil {
+		if best, _, err := nearestCategory(ctx, b.categoryEmbedder, b.categoryCache, guess, candidates); err == nil {
+			return best
+		}
+		return guess
+	}
+
+	if best, ok := nearestCategoryByKeyword(guess, candidates); ok {
+		return best
+	}
+	return guess
+}
+
+// askExpenseConfirmation stashes a low-confidence parse and asks the user to
+// confirm it before anything is written to Sheets.
+func (b *Bot) askExpenseConfirmation(message *tgbotapi.Message, lang string, amount float64, category string, isCard bool) {
+	b.pendingExpenses.set(message.From.ID, pendingExpense{
+		message:  message,
+		lang:     lang,
+		amount:   amount,
+		category: category,
+		isCard:   isCard,
+	})
+
+	paymentMethod := b.messages[lang]["payment_cash"]
+	if isCard {
+		paymentMethod = b.messages[lang]["payment_card"]
+	}
+
+	prompt := fmt.Sprintf(b.messages[lang]["confirm_expense_prompt"], amount, category, paymentMethod)
+	buttons := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.messages[lang]["confirm_yes"], fmt.Sprintf("confirm_expense:%d", message.From.ID)),
+		tgbotapi.NewInlineKeyboardButtonData(b.messages[lang]["confirm_no"], fmt.Sprintf("cancel_expense:%d", message.From.ID)),
+	))
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, prompt)
+	msg.ReplyMarkup = buttons
+	if _, err := b.api.Send(msg); err != nil {
+		log.Printf("Failed to send expense confirmation: %v", err)
+	}
+}
+
+// handleExpenseConfirmation applies or discards the pending expense for
+// whoever tapped the button, scoped to cq.From.ID like every other
+// callback handler — the userID embedded in cq.Data is only ever used to
+// address the button at the right chat, never to decide whose expense gets confirmed.
+func (b *Bot) handleExpenseConfirmation(ctx context.Context, cq *tgbotapi.CallbackQuery) {
+	defer b.answerCallback(cq)
+
+	parts := strings.SplitN(cq.Data, ":", 2)
+	if len(parts) != 2 {
+		return
+	}
+	action := parts[0]
+
+	pending, ok := b.pendingExpenses.getAndDelete(cq.From.ID)
+	if !ok {
+		return
+	}
+
+	if action == "cancel_expense" {
+		b.sendMessage(cq.Message.Chat.ID, b.messages[pending.lang]["confirm_cancelled"])
+		return
+	}
+
+	b.finalizeExpense(ctx, pending.message.Chat.ID, pending.message.From.ID, pending.lang, pending.amount, pending.category, pending.isCard)
+}
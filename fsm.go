@@ -0,0 +1,138 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	_ "modernc.org/sqlite"
+)
+
+// GuidedState is a step in the guided /add conversation started when /add is
+// sent with no arguments.
+type GuidedState string
+
+const (
+	StateAwaitingAmount   GuidedState = "awaiting_amount"
+	StateAwaitingPayment  GuidedState = "awaiting_payment"
+	StateAwaitingCategory GuidedState = "awaiting_category"
+	StateConfirming       GuidedState = "confirming"
+)
+
+// GuidedSession tracks one user's progress through the guided /add flow.
+// Categories is the CategoryRange snapshot fetched once the payment method
+// is picked, so flipping pages doesn't re-read Sheets.
+type GuidedSession struct {
+	UserID     int64
+	ChatID     int64
+	Lang       string
+	State      GuidedState
+	Amount     float64
+	IsCard     bool
+	Category   string
+	Categories []string
+	Page       int
+}
+
+// FSMStore holds each user's GuidedSession in memory for fast access during
+// the conversation, persisting every change to SQLite so an in-progress
+// guided flow survives a bot restart.
+type FSMStore struct {
+	mu       sync.Mutex
+	sessions map[int64]GuidedSession
+	db       *sql.DB
+}
+
+func NewFSMStore(path string) (*FSMStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open guided session store: %w", err)
+	}
+
+	const schema = `
+	CREATE TABLE IF NOT EXISTS guided_sessions (
+		user_id INTEGER PRIMARY KEY,
+		data    TEXT NOT NULL
+	);`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to init guided session schema: %w", err)
+	}
+
+	store := &FSMStore{sessions: make(map[int64]GuidedSession), db: db}
+	if err := store.loadAll(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *FSMStore) loadAll() error {
+	rows, err := s.db.Query(`SELECT user_id, data FROM guided_sessions`)
+	if err != nil {
+		return fmt.Errorf("failed to load guided sessions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int64
+		var data string
+		if err := rows.Scan(&userID, &data); err != nil {
+			return fmt.Errorf("failed to scan guided session: %w", err)
+		}
+		var session GuidedSession
+		if err := json.Unmarshal([]byte(data), &session); err != nil {
+			continue // drop a corrupt row rather than fail startup over it
+		}
+		s.sessions[userID] = session
+	}
+	return rows.Err()
+}
+
+// Get returns userID's in-progress guided session, if any.
+func (s *FSMStore) Get(userID int64) (GuidedSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[userID]
+	return session, ok
+}
+
+// Save upserts session both in memory and on disk.
+func (s *FSMStore) Save(session GuidedSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal guided session: %w", err)
+	}
+
+	s.mu.Lock()
+	s.sessions[session.UserID] = session
+	s.mu.Unlock()
+
+	_, err = s.db.Exec(
+		`INSERT INTO guided_sessions (user_id, data) VALUES (?, ?)
+		 ON CONFLICT(user_id) DO UPDATE SET data = excluded.data`,
+		session.UserID, string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to persist guided session for user %d: %w", session.UserID, err)
+	}
+	return nil
+}
+
+// Delete clears userID's guided session, once the flow completes or is cancelled.
+func (s *FSMStore) Delete(userID int64) error {
+	s.mu.Lock()
+	delete(s.sessions, userID)
+	s.mu.Unlock()
+
+	_, err := s.db.Exec(`DELETE FROM guided_sessions WHERE user_id = ?`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete guided session for user %d: %w", userID, err)
+	}
+	return nil
+}
+
+func (s *FSMStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// categoriesPerPage keeps each keyboard well under Telegram's 100-button
+// cap on a callback keyboard while still fitting comfortably on a phone
+// screen.
+const categoriesPerPage = 8
+
+// startGuidedAdd begins the guided /add flow for a user who sent /add with
+// no arguments, asking for the amount first.
+func (b *Bot) startGuidedAdd(message *tgbotapi.Message, lang string) {
+	session := GuidedSession{
+		UserID: message.From.ID,
+		ChatID: message.Chat.ID,
+		Lang:   lang,
+		State:  StateAwaitingAmount,
+	}
+	if err := b.guidedSessions.Save(session); err != nil {
+		log.Printf("Error starting guided add: %v", err)
+	}
+	b.sendMessage(message.Chat.ID, b.messages[lang]["guided_amount_prompt"])
+}
+
+// handleGuidedAmountInput consumes the free-text reply expected while a
+// session is awaiting_amount, then moves on to the payment-method keyboard.
+func (b *Bot) handleGuidedAmountInput(ctx context.Context, message *tgbotapi.Message, lang string, session GuidedSession) {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(message.Text), 64)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["invalid_amount"])
+		return
+	}
+
+	session.Amount = amount
+	session.State = StateAwaitingPayment
+	if err := b.guidedSessions.Save(session); err != nil {
+		log.Printf("Error saving guided session: %v", err)
+	}
+
+	buttons := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.messages[lang]["payment_cash"], "pay:cash"),
+		tgbotapi.NewInlineKeyboardButtonData(b.messages[lang]["payment_card"], "pay:card"),
+	))
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.messages[lang]["guided_payment_prompt"])
+	msg.ReplyMarkup = buttons
+	if _, err := b.api.Send(msg); err != nil {
+		log.Printf("Failed to send guided payment prompt: %v", err)
+	}
+}
+
+// handleGuidedPayment applies the chosen payment method, fetches the user's
+// CategoryRange once and shows the first page of the category keyboard.
+func (b *Bot) handleGuidedPayment(ctx context.Context, cq *tgbotapi.CallbackQuery) {
+	defer b.answerCallback(cq)
+
+	session, ok := b.guidedSessions.Get(cq.From.ID)
+	if !ok || session.State != StateAwaitingPayment {
+		return
+	}
+	session.IsCard = cq.Data == "pay:card"
+
+	sheetsClient, userConfig, err := b.resolveSheetsClient(ctx, cq.From.ID)
+	if err != nil {
+		b.sendMessage(cq.Message.Chat.ID, b.messages[session.Lang]["not_connected"])
+		b.clearGuidedSession(cq.From.ID)
+		return
+	}
+
+	sheetName := b.getSheetName(session.Lang)
+	categories, err := sheetsClient.listCategories(ctx, userConfig, sheetName)
+	if err != nil || len(categories) == 0 {
+		log.Printf("Error listing categories for guided add: %v", err)
+		b.sendMessage(cq.Message.Chat.ID, fmt.Sprintf(b.messages[session.Lang]["error_occurred"], err))
+		b.clearGuidedSession(cq.From.ID)
+		return
+	}
+
+	session.Categories = categories
+	session.Page = 0
+	session.State = StateAwaitingCategory
+	if err := b.guidedSessions.Save(session); err != nil {
+		log.Printf("Error saving guided session: %v", err)
+	}
+
+	b.sendCategoryPage(cq.Message.Chat.ID, session)
+}
+
+// handleGuidedCategoryPage flips the category keyboard to another page in
+// place, without re-fetching Sheets or resending the prompt text.
+func (b *Bot) handleGuidedCategoryPage(ctx context.Context, cq *tgbotapi.CallbackQuery) {
+	defer b.answerCallback(cq)
+
+	session, ok := b.guidedSessions.Get(cq.From.ID)
+	if !ok || session.State != StateAwaitingCategory {
+		return
+	}
+
+	page, err := strconv.Atoi(strings.TrimPrefix(cq.Data, "page:"))
+	if err != nil {
+		return
+	}
+	session.Page = page
+	if err := b.guidedSessions.Save(session); err != nil {
+		log.Printf("Error saving guided session: %v", err)
+	}
+
+	edit := tgbotapi.NewEditMessageReplyMarkup(cq.Message.Chat.ID, cq.Message.MessageID, categoryKeyboard(session))
+	if _, err := b.api.Request(edit); err != nil {
+		log.Printf("Failed to edit category page: %v", err)
+	}
+}
+
+// handleGuidedCategory records the chosen category and asks for final
+// confirmation before anything is written to Sheets.
+func (b *Bot) handleGuidedCategory(ctx context.Context, cq *tgbotapi.CallbackQuery) {
+	defer b.answerCallback(cq)
+
+	session, ok := b.guidedSessions.Get(cq.From.ID)
+	if !ok || session.State != StateAwaitingCategory {
+		return
+	}
+
+	idx, err := strconv.Atoi(strings.TrimPrefix(cq.Data, "cat:"))
+	if err != nil || idx < 0 || idx >= len(session.Categories) {
+		return
+	}
+
+	session.Category = session.Categories[idx]
+	session.State = StateConfirming
+	if err := b.guidedSessions.Save(session); err != nil {
+		log.Printf("Error saving guided session: %v", err)
+	}
+
+	paymentMethod := b.messages[session.Lang]["payment_cash"]
+	if session.IsCard {
+		paymentMethod = b.messages[session.Lang]["payment_card"]
+	}
+
+	prompt := fmt.Sprintf(b.messages[session.Lang]["confirm_expense_prompt"], session.Amount, session.Category, paymentMethod)
+	buttons := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.messages[session.Lang]["confirm_yes"], "confirm:add"),
+		tgbotapi.NewInlineKeyboardButtonData(b.messages[session.Lang]["confirm_no"], "cancel:add"),
+	))
+
+	msg := tgbotapi.NewMessage(cq.Message.Chat.ID, prompt)
+	msg.ReplyMarkup = buttons
+	if _, err := b.api.Send(msg); err != nil {
+		log.Printf("Failed to send guided confirmation: %v", err)
+	}
+}
+
+// handleGuidedConfirm writes the assembled expense via the normal
+// finalizeExpense path, shared with /add, free text and the scheduler.
+func (b *Bot) handleGuidedConfirm(ctx context.Context, cq *tgbotapi.CallbackQuery) {
+	defer b.answerCallback(cq)
+
+	session, ok := b.guidedSessions.Get(cq.From.ID)
+	if !ok || session.State != StateConfirming {
+		return
+	}
+	b.clearGuidedSession(cq.From.ID)
+
+	b.finalizeExpense(ctx, session.ChatID, session.UserID, session.Lang, session.Amount, session.Category, session.IsCard)
+}
+
+// handleGuidedCancel discards an in-progress guided session at any step.
+func (b *Bot) handleGuidedCancel(ctx context.Context, cq *tgbotapi.CallbackQuery) {
+	defer b.answerCallback(cq)
+
+	session, ok := b.guidedSessions.Get(cq.From.ID)
+	if !ok {
+		return
+	}
+	b.clearGuidedSession(cq.From.ID)
+
+	b.sendMessage(cq.Message.Chat.ID, b.messages[session.Lang]["confirm_cancelled"])
+}
+
+func (b *Bot) clearGuidedSession(userID int64) {
+	if err := b.guidedSessions.Delete(userID); err != nil {
+		log.Printf("Error clearing guided session for user %d: %v", userID, err)
+	}
+}
+
+func (b *Bot) sendCategoryPage(chatID int64, session GuidedSession) {
+	msg := tgbotapi.NewMessage(chatID, b.messages[session.Lang]["guided_category_prompt"])
+	msg.ReplyMarkup = categoryKeyboard(session)
+	if _, err := b.api.Send(msg); err != nil {
+		log.Printf("Failed to send category page: %v", err)
+	}
+}
+
+// categoryKeyboard renders session's current page of Categories, one button
+// per row, plus a nav row with « prev / next » buttons where applicable.
+// Each button encodes the category's index into the full Categories slice
+// (not the page-relative position), so flipping pages never shifts what a
+// given cat: callback resolves to.
+func categoryKeyboard(session GuidedSession) tgbotapi.InlineKeyboardMarkup {
+	start := session.Page * categoriesPerPage
+	end := start + categoriesPerPage
+	if end > len(session.Categories) {
+		end = len(session.Categories)
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := start; i < end; i++ {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(session.Categories[i], fmt.Sprintf("cat:%d", i)),
+		))
+	}
+
+	var nav []tgbotapi.InlineKeyboardButton
+	if session.Page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("« prev", fmt.Sprintf("page:%d", session.Page-1)))
+	}
+	if end < len(session.Categories) {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("next »", fmt.Sprintf("page:%d", session.Page+1)))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Transaction is a single recorded /add, kept so it can be listed, undone or edited later.
+type Transaction struct {
+	ID            int64
+	UserID        int64
+	Timestamp     time.Time
+	Amount        float64
+	Category      string
+	IsCard        bool
+	SheetName     string
+	DailyCell     string
+	CategoryCell  string
+	FormattedTerm string
+	Undone        bool
+	// ReceiptFileID is the Telegram file_id of the scanned receipt a
+	// transaction was split from, empty for transactions added any other way.
+	ReceiptFileID string
+}
+
+// HistoryStore persists the transaction ledger backing /history, /undo and /edit.
+type HistoryStore struct {
+	db *sql.DB
+}
+
+// NewHistoryStore opens (and if needed creates) the local SQLite ledger at path.
+func NewHistoryStore(path string) (*HistoryStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history store: %w", err)
+	}
+
+	const schema = `
+	CREATE TABLE IF NOT EXISTS transactions (
+		id             INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id        INTEGER NOT NULL,
+		timestamp      DATETIME NOT NULL,
+		amount         REAL NOT NULL,
+		category       TEXT NOT NULL,
+		is_card        INTEGER NOT NULL,
+		sheet_name     TEXT NOT NULL,
+		daily_cell     TEXT NOT NULL,
+		category_cell  TEXT NOT NULL,
+		formatted_term TEXT NOT NULL,
+		undone         INTEGER NOT NULL DEFAULT 0
+	);`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to init history schema: %w", err)
+	}
+
+	// receipt_file_id was added after the initial release; ALTER TABLE is
+	// the simplest way to bring an existing history.db up to date, and
+	// sqlite has no "ADD COLUMN IF NOT EXISTS", so a "duplicate column"
+	// error here just means a prior run already migrated it.
+	if _, err := db.Exec(`ALTER TABLE transactions ADD COLUMN receipt_file_id TEXT NOT NULL DEFAULT ''`); err != nil &&
+		!strings.Contains(err.Error(), "duplicate column") {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate history schema: %w", err)
+	}
+
+	return &HistoryStore{db: db}, nil
+}
+
+// Record stores a completed transaction and returns its assigned ID.
+func (hs *HistoryStore) Record(tx Transaction) (int64, error) {
+	res, err := hs.db.Exec(
+		`INSERT INTO transactions (user_id, timestamp, amount, category, is_card, sheet_name, daily_cell, category_cell, formatted_term, receipt_file_id)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		tx.UserID, tx.Timestamp, tx.Amount, tx.Category, tx.IsCard, tx.SheetName, tx.DailyCell, tx.CategoryCell, tx.FormattedTerm, tx.ReceiptFileID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record transaction: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// Recent returns the user's last n non-undone transactions, newest first.
+func (hs *HistoryStore) Recent(userID int64, n int) ([]Transaction, error) {
+	rows, err := hs.db.Query(
+		`SELECT id, user_id, timestamp, amount, category, is_card, sheet_name, daily_cell, category_cell, formatted_term, undone, receipt_file_id
+		 FROM transactions WHERE user_id = ? AND undone = 0 ORDER BY id DESC LIMIT ?`,
+		userID, n,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	var txs []Transaction
+	for rows.Next() {
+		var tx Transaction
+		if err := rows.Scan(&tx.ID, &tx.UserID, &tx.Timestamp, &tx.Amount, &tx.Category, &tx.IsCard, &tx.SheetName, &tx.DailyCell, &tx.CategoryCell, &tx.FormattedTerm, &tx.Undone, &tx.ReceiptFileID); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		txs = append(txs, tx)
+	}
+	return txs, rows.Err()
+}
+
+// LastActive returns the user's most recent non-undone transaction.
+func (hs *HistoryStore) LastActive(userID int64) (Transaction, error) {
+	txs, err := hs.Recent(userID, 1)
+	if err != nil {
+		return Transaction{}, err
+	}
+	if len(txs) == 0 {
+		return Transaction{}, sql.ErrNoRows
+	}
+	return txs[0], nil
+}
+
+// Get looks up a single transaction owned by userID.
+func (hs *HistoryStore) Get(userID, id int64) (Transaction, error) {
+	var tx Transaction
+	row := hs.db.QueryRow(
+		`SELECT id, user_id, timestamp, amount, category, is_card, sheet_name, daily_cell, category_cell, formatted_term, undone, receipt_file_id
+		 FROM transactions WHERE id = ? AND user_id = ?`,
+		id, userID,
+	)
+	if err := row.Scan(&tx.ID, &tx.UserID, &tx.Timestamp, &tx.Amount, &tx.Category, &tx.IsCard, &tx.SheetName, &tx.DailyCell, &tx.CategoryCell, &tx.FormattedTerm, &tx.Undone, &tx.ReceiptFileID); err != nil {
+		return Transaction{}, fmt.Errorf("failed to get transaction %d: %w", id, err)
+	}
+	return tx, nil
+}
+
+// MarkUndone flags a transaction so it no longer shows up in /history or can be undone twice.
+func (hs *HistoryStore) MarkUndone(id int64) error {
+	_, err := hs.db.Exec(`UPDATE transactions SET undone = 1 WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark transaction %d undone: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateAmount rewrites the stored amount and formula term after a successful /edit.
+func (hs *HistoryStore) UpdateAmount(id int64, amount float64, formattedTerm string) error {
+	_, err := hs.db.Exec(`UPDATE transactions SET amount = ?, formatted_term = ? WHERE id = ?`, amount, formattedTerm, id)
+	if err != nil {
+		return fmt.Errorf("failed to update transaction %d: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateCategory rewrites the stored category after a successful /edit.
+func (hs *HistoryStore) UpdateCategory(id int64, category, categoryCell string) error {
+	_, err := hs.db.Exec(`UPDATE transactions SET category = ?, category_cell = ? WHERE id = ?`, category, categoryCell, id)
+	if err != nil {
+		return fmt.Errorf("failed to update transaction %d: %w", id, err)
+	}
+	return nil
+}
+
+func (hs *HistoryStore) Close() error {
+	return hs.db.Close()
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// /history [N]
+func (b *Bot) handleHistoryCommand(message *tgbotapi.Message, lang string) {
+	limit := defaultHistoryLimit
+	if args := strings.TrimSpace(message.CommandArguments()); args != "" {
+		if n, err := strconv.Atoi(args); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	txs, err := b.history.Recent(message.From.ID, limit)
+	if err != nil {
+		log.Printf("Error loading history: %v", err)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["error_occurred"], err))
+		return
+	}
+
+	if len(txs) == 0 {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["history_empty"])
+		return
+	}
+
+	var sb strings.Builder
+	for _, tx := range txs {
+		paymentMethod := b.messages[lang]["payment_cash"]
+		if tx.IsCard {
+			paymentMethod = b.messages[lang]["payment_card"]
+		}
+		sb.WriteString(fmt.Sprintf(b.messages[lang]["history_entry"], tx.ID, tx.Timestamp.Format("2006-01-02 15:04"), tx.Amount, tx.Category, paymentMethod))
+		sb.WriteString("\n")
+	}
+	b.sendMessage(message.Chat.ID, sb.String())
+}
+
+// /undo reverses the user's most recent non-undone transaction.
+func (b *Bot) handleUndoCommand(ctx context.Context, message *tgbotapi.Message, lang string) {
+	tx, err := b.history.LastActive(message.From.ID)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["undo_nothing"])
+		return
+	}
+
+	sheetsClient, userConfig, err := b.resolveSheetsClient(ctx, message.From.ID)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["not_connected"])
+		return
+	}
+
+	if err := sheetsClient.undoTransaction(ctx, userConfig, tx); err != nil {
+		log.Printf("Error undoing transaction %d: %v", tx.ID, err)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["error_occurred"], err))
+		return
+	}
+
+	if err := b.history.MarkUndone(tx.ID); err != nil {
+		log.Printf("Error marking transaction %d undone: %v", tx.ID, err)
+	}
+
+	b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["undo_done"], tx.Amount, tx.Category))
+}
+
+// /edit <id> <new amount|category>
+func (b *Bot) handleEditCommand(ctx context.Context, message *tgbotapi.Message, lang string) {
+	fields := strings.Fields(message.CommandArguments())
+	if len(fields) < 2 {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["edit_usage"])
+		return
+	}
+
+	id, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["edit_usage"])
+		return
+	}
+
+	tx, err := b.history.Get(message.From.ID, id)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["edit_not_found"])
+		return
+	}
+
+	sheetsClient, userConfig, err := b.resolveSheetsClient(ctx, message.From.ID)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["not_connected"])
+		return
+	}
+
+	newValue := strings.Join(fields[1:], " ")
+	if amount, err := strconv.ParseFloat(newValue, 64); err == nil {
+		if err := sheetsClient.editTransactionAmount(ctx, userConfig, tx, amount); err != nil {
+			log.Printf("Error editing transaction %d: %v", tx.ID, err)
+			b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["error_occurred"], err))
+			return
+		}
+		newTerm := sheetsClient.formatAmount(amount, tx.IsCard)
+		if err := b.history.UpdateAmount(tx.ID, amount, newTerm); err != nil {
+			log.Printf("Error updating transaction %d: %v", tx.ID, err)
+		}
+		b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["edit_done"], tx.ID))
+		return
+	}
+
+	newCell, err := sheetsClient.editTransactionCategory(ctx, userConfig, tx, newValue, lang, b.messages)
+	if err != nil {
+		log.Printf("Error editing transaction %d: %v", tx.ID, err)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["error_occurred"], err))
+		return
+	}
+	if err := b.history.UpdateCategory(tx.ID, newValue, newCell); err != nil {
+		log.Printf("Error updating transaction %d: %v", tx.ID, err)
+	}
+	b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["edit_done"], tx.ID))
+}
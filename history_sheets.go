@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// undoTransaction removes tx's formula term from both the daily and the
+// category cell it was written to, reversing a /add without touching any
+// other term that has since been appended to the same cell.
+func (sc *SheetsClient) undoTransaction(ctx context.Context, config Config, tx Transaction) error {
+	if err := sc.removeCellTerm(ctx, config.SpreadsheetID, tx.DailyCell, tx.FormattedTerm); err != nil {
+		return fmt.Errorf("failed to undo daily cell: %w", err)
+	}
+	if err := sc.removeCellTerm(ctx, config.SpreadsheetID, tx.CategoryCell, tx.FormattedTerm); err != nil {
+		return fmt.Errorf("failed to undo category cell: %w", err)
+	}
+	return nil
+}
+
+// editTransactionAmount replaces tx's formula term with one built from the
+// new amount, in both the daily and the category cell.
+func (sc *SheetsClient) editTransactionAmount(ctx context.Context, config Config, tx Transaction, newAmount float64) error {
+	newTerm := sc.formatAmount(newAmount, tx.IsCard)
+	if err := sc.replaceCellTerm(ctx, config.SpreadsheetID, tx.DailyCell, tx.FormattedTerm, newTerm); err != nil {
+		return fmt.Errorf("failed to edit daily cell: %w", err)
+	}
+	if err := sc.replaceCellTerm(ctx, config.SpreadsheetID, tx.CategoryCell, tx.FormattedTerm, newTerm); err != nil {
+		return fmt.Errorf("failed to edit category cell: %w", err)
+	}
+	return nil
+}
+
+// editTransactionCategory moves tx's term from its old category cell to the
+// cell for newCategory, leaving the daily cell untouched. It returns the new
+// category cell address so the caller can persist it alongside the history record.
+func (sc *SheetsClient) editTransactionCategory(ctx context.Context, config Config, tx Transaction, newCategory, lang string, messages Messages) (string, error) {
+	if err := sc.removeCellTerm(ctx, config.SpreadsheetID, tx.CategoryCell, tx.FormattedTerm); err != nil {
+		return "", fmt.Errorf("failed to remove term from old category cell: %w", err)
+	}
+
+	newCell, err := sc.writeExpenseToCategoryCell(ctx, config, tx.SheetName, newCategory, tx.Amount, tx.IsCard, lang, messages)
+	if err != nil {
+		return "", fmt.Errorf("failed to add term to new category cell: %w", err)
+	}
+	return newCell, nil
+}
+
+// removeCellTerm reads cell's formula, removes target and writes the result back.
+func (sc *SheetsClient) removeCellTerm(ctx context.Context, spreadsheetID, cell, target string) error {
+	currentValue, err := sc.getCellValue(ctx, spreadsheetID, cell, "FORMULA")
+	if err != nil {
+		return err
+	}
+
+	newValue, err := sc.removeFormulaTerm(currentValue, target)
+	if err != nil {
+		return err
+	}
+
+	if newValue == "" {
+		return sc.updateCellValue(ctx, spreadsheetID, cell, "0")
+	}
+	return sc.updateCellValue(ctx, spreadsheetID, cell, fmt.Sprintf("=%s", newValue))
+}
+
+// replaceCellTerm reads cell's formula, swaps oldTerm for newTerm and writes it back.
+func (sc *SheetsClient) replaceCellTerm(ctx context.Context, spreadsheetID, cell, oldTerm, newTerm string) error {
+	currentValue, err := sc.getCellValue(ctx, spreadsheetID, cell, "FORMULA")
+	if err != nil {
+		return err
+	}
+
+	newValue, err := sc.replaceFormulaTerm(currentValue, oldTerm, newTerm)
+	if err != nil {
+		return err
+	}
+
+	return sc.updateCellValue(ctx, spreadsheetID, cell, fmt.Sprintf("=%s", newValue))
+}
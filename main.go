@@ -2,53 +2,103 @@ package main
 
 import (
 	"context"
+	"crypto/cipher"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
-	"google.golang.org/api/option"
 	"google.golang.org/api/sheets/v4"
 )
 
 const (
-	commandStart  = "start"
-	commandAdd    = "add"
-	commandBudget = "budget"
-	commandLang   = "lang"
-	commandHelp   = "help"
+	commandStart     = "start"
+	commandAdd       = "add"
+	commandBudget    = "budget"
+	commandLang      = "lang"
+	commandHelp      = "help"
+	commandHistory   = "history"
+	commandUndo      = "undo"
+	commandEdit      = "edit"
+	commandConnect   = "connect"
+	commandRecurring = "recurring"
+	commandTz        = "tz"
+	commandReminders = "reminders"
+	commandPending   = "pending"
 )
 
+// defaultHistoryLimit is how many entries /history shows when no N is given.
+const defaultHistoryLimit = 10
+
 // Cloud Expenses Bot for personal budget usage
 type Bot struct {
-	api          *tgbotapi.BotAPI
-	sheetsClient *SheetsClient
-	config       Config
-	messages     Messages
-	userLang     map[int64]string
+	api      *tgbotapi.BotAPI
+	config   Config
+	messages Messages
+	userLang map[int64]string
+	history  *HistoryStore
+
+	recurring       *RecurringStore
+	preferences     *PreferencesStore
+	outbox          *OutboxStore
+	guidedSessions  *FSMStore
+	receiptSessions *receiptSessions
+
+	categoryRowCache *categoryRowCache
+	receiptExtractor ReceiptExtractor
+
+	userStore      *UserStore
+	oauthConfig    *oauth2.Config
+	tokenCipher    cipher.AEAD
+	tokenSourcesMu sync.Mutex
+	tokenSources   map[int64]oauth2.TokenSource
+
+	parser           Parser
+	categoryEmbedder Embedder // nil when the configured parser has no embeddings backend
+	categoryCache    *embeddingCache
+	pendingExpenses  *pendingExpenseStore
 }
 
 // Localication
 type Messages map[string]map[string]string
 
+// CellRanges locates the cells a user's spreadsheet keeps its ledger in. A
+// user may override these via a /connect with custom ranges; otherwise the
+// bot's default from config.json applies.
+type CellRanges struct {
+	DailyExpenses  string `json:"daily_expenses"`
+	CategoryRange  string `json:"category_range"`
+	CategoryColumn string `json:"category_column"`
+	BudgetColumn   string `json:"budget_column"`
+}
+
+// OAuthConfig configures the shared Google OAuth client every tenant
+// authorizes against; only the resulting per-user token differs.
+type OAuthConfig struct {
+	CredentialsPath string `json:"credentials_path"` // defaults to credentials.json
+	CallbackHost    string `json:"callback_host"`    // e.g. https://bot.example.com, defaults to http://localhost:<port>
+	CallbackPort    int    `json:"callback_port"`    // defaults to 8085
+}
+
 // JSON configs
 type Config struct {
-	SpreadsheetID string `json:"spreadsheet_id"`
-	BotToken      string `json:"bot_token"`
-	CellRanges    struct {
-		DailyExpenses  string `json:"daily_expenses"`
-		CategoryRange  string `json:"category_range"`
-		CategoryColumn string `json:"category_column"`
-		BudgetColumn   string `json:"budget_column"`
-	} `json:"cell_ranges"`
+	// SpreadsheetID and CellRanges are resolved per request from the
+	// requesting user's UserStore record, not read from config.json; a
+	// Config value carrying them is still what SheetsClient methods take,
+	// so the per-user values are copied in here at call time.
+	SpreadsheetID string        `json:"-"`
+	BotToken      string        `json:"bot_token"`
+	CellRanges    CellRanges    `json:"cell_ranges"` // defaults used when a user has no override
+	Parser        ParserConfig  `json:"parser"`
+	OAuth         OAuthConfig   `json:"oauth"`
+	Receipt       ReceiptConfig `json:"receipt"`
 }
 
 // Google Sheets API
@@ -67,7 +117,17 @@ func NewBot(ctx context.Context) (*Bot, error) {
 		return nil, fmt.Errorf("failed to create bot API: %w", err)
 	}
 
-	sheetsClient, err := NewSheetsClient(ctx)
+	oauthConfig, err := loadOAuthConfig(config.OAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenCipher, err := loadTokenCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	userStore, err := NewUserStore("users.db")
 	if err != nil {
 		return nil, err
 	}
@@ -77,37 +137,68 @@ func NewBot(ctx context.Context) (*Bot, error) {
 		return nil, err
 	}
 
-	return &Bot{
-		api:          api,
-		sheetsClient: sheetsClient,
-		config:       config,
-		messages:     messages,
-		userLang:     make(map[int64]string),
-	}, nil
-}
+	history, err := NewHistoryStore("history.db")
+	if err != nil {
+		return nil, err
+	}
+
+	recurring, err := NewRecurringStore("recurring.db")
+	if err != nil {
+		return nil, err
+	}
 
-func NewSheetsClient(ctx context.Context) (*SheetsClient, error) {
-	creds, err := os.ReadFile("credentials.json")
+	preferences, err := NewPreferencesStore("preferences.db")
 	if err != nil {
-		return nil, fmt.Errorf("failed to read credentials.json: %w", err)
+		return nil, err
 	}
 
-	config, err := google.ConfigFromJSON(creds, sheets.SpreadsheetsScope)
+	outbox, err := NewOutboxStore("outbox.db")
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse Google config: %w", err)
+		return nil, err
 	}
 
-	client, err := getClient(ctx, config)
+	guidedSessions, err := NewFSMStore("guided_sessions.db")
 	if err != nil {
 		return nil, err
 	}
 
-	service, err := sheets.NewService(ctx, option.WithHTTPClient(client))
+	parser, err := NewParser(config.Parser)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Sheets service: %w", err)
+		return nil, err
+	}
+
+	var categoryEmbedder Embedder
+	if config.Parser.Provider == "openai" {
+		categoryEmbedder = newOpenAIEmbedder(config.Parser)
+	}
+
+	receiptExtractor, err := NewReceiptExtractor(config.Receipt)
+	if err != nil {
+		return nil, err
 	}
 
-	return &SheetsClient{service: service}, nil
+	return &Bot{
+		api:              api,
+		config:           config,
+		messages:         messages,
+		userLang:         make(map[int64]string),
+		history:          history,
+		recurring:        recurring,
+		preferences:      preferences,
+		outbox:           outbox,
+		guidedSessions:   guidedSessions,
+		receiptSessions:  newReceiptSessions(),
+		categoryRowCache: newCategoryRowCache(),
+		receiptExtractor: receiptExtractor,
+		userStore:        userStore,
+		oauthConfig:      oauthConfig,
+		tokenCipher:      tokenCipher,
+		tokenSources:     make(map[int64]oauth2.TokenSource),
+		parser:           parser,
+		categoryEmbedder: categoryEmbedder,
+		categoryCache:    newEmbeddingCache(),
+		pendingExpenses:  newPendingExpenseStore(),
+	}, nil
 }
 
 func loadConfig(path string) (Config, error) {
@@ -134,77 +225,6 @@ func loadMessages(path string) (Messages, error) {
 	return messages, nil
 }
 
-func getClient(ctx context.Context, config *oauth2.Config) (*http.Client, error) {
-	tokenFile := "token.json"
-	token, err := tokenFromFile(tokenFile)
-	if err != nil {
-		token, err = getTokenFromWeb(config)
-		if err != nil {
-			return nil, err
-		}
-		if err := saveToken(tokenFile, token); err != nil {
-			return nil, err
-		}
-	}
-
-	if !token.Valid() {
-		tokenSource := config.TokenSource(ctx, token)
-		token, err = tokenSource.Token()
-		if err != nil {
-			return nil, fmt.Errorf("failed to refresh token: %w", err)
-		}
-		if err := saveToken(tokenFile, token); err != nil {
-			return nil, err
-		}
-	}
-
-	return config.Client(ctx, token), nil
-}
-
-func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	fmt.Printf("Visit the URL for authorization:\n%v\n", authURL)
-
-	fmt.Print("Enter authorization code: ")
-	var authCode string
-	if _, err := fmt.Scan(&authCode); err != nil {
-		return nil, fmt.Errorf("failed to read authorization code: %w", err)
-	}
-
-	token, err := config.Exchange(context.Background(), authCode)
-	if err != nil {
-		return nil, fmt.Errorf("failed to exchange token: %w", err)
-	}
-	return token, nil
-}
-
-func saveToken(path string, token *oauth2.Token) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("failed to create token file %s: %w", path, err)
-	}
-	defer file.Close()
-
-	if err := json.NewEncoder(file).Encode(token); err != nil {
-		return fmt.Errorf("failed to encode token: %w", err)
-	}
-	return nil
-}
-
-func tokenFromFile(path string) (*oauth2.Token, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	var token oauth2.Token
-	if err := json.NewDecoder(file).Decode(&token); err != nil {
-		return nil, err
-	}
-	return &token, nil
-}
-
 func (b *Bot) getSheetName(lang string) string {
 	now := time.Now()
 	year := now.Year()
@@ -216,6 +236,8 @@ func (b *Bot) getSheetName(lang string) string {
 }
 
 func (b *Bot) Start(ctx context.Context) {
+	go b.startScheduler(ctx)
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 	updates := b.api.GetUpdatesChan(u)
@@ -224,9 +246,7 @@ func (b *Bot) Start(ctx context.Context) {
 		if update.Message != nil {
 			go b.handleUpdate(ctx, update.Message)
 		} else if update.CallbackQuery != nil {
-			go func() {
-				b.handleCallbackQuery(update.CallbackQuery)
-			}()
+			go b.handleCallbackQuery(ctx, update.CallbackQuery)
 		}
 	}
 }
@@ -247,16 +267,72 @@ func (b *Bot) handleUpdate(ctx context.Context, message *tgbotapi.Message) {
 			b.handleLangCommand(message)
 		case commandHelp:
 			b.handleHelpCommand(message)
+		case commandHistory:
+			b.handleHistoryCommand(message, lang)
+		case commandUndo:
+			b.handleUndoCommand(ctx, message, lang)
+		case commandEdit:
+			b.handleEditCommand(ctx, message, lang)
+		case commandConnect:
+			b.handleConnectCommand(message, lang)
+		case commandRecurring:
+			b.handleRecurringCommand(message, lang)
+		case commandTz:
+			b.handleTzCommand(message, lang)
+		case commandReminders:
+			b.handleRemindersCommand(message, lang)
+		case commandPending:
+			b.handlePendingCommand(message, lang)
 		default:
 			b.sendMessage(message.Chat.ID, b.messages[lang]["unknown_command"])
 		}
+	} else if len(message.Photo) > 0 || (message.Document != nil && strings.HasPrefix(message.Document.MimeType, "image/")) {
+		b.handleReceiptPhoto(ctx, message, lang)
+	} else if strings.TrimSpace(message.Text) != "" {
+		if session, ok := b.guidedSessions.Get(userID); ok && session.State == StateAwaitingAmount {
+			b.handleGuidedAmountInput(ctx, message, lang, session)
+		} else {
+			b.handleFreeText(ctx, message, lang)
+		}
 	} else {
 		b.sendMessage(message.Chat.ID, b.messages[lang]["start"])
 	}
 }
 
-func (b *Bot) handleCallbackQuery(cq *tgbotapi.CallbackQuery) {
+// handleCallbackQuery dispatches an inline-keyboard tap by its callback
+// data's prefix: confirm_expense:/cancel_expense: for the free-text
+// low-confidence flow, pay:/cat:/page:/confirm:/cancel: for the guided /add
+// flow, and the bare help_ru/help_en values from /help.
+func (b *Bot) handleCallbackQuery(ctx context.Context, cq *tgbotapi.CallbackQuery) {
 	data := cq.Data
+
+	switch {
+	case strings.HasPrefix(data, "confirm_expense:"), strings.HasPrefix(data, "cancel_expense:"):
+		b.handleExpenseConfirmation(ctx, cq)
+		return
+	case strings.HasPrefix(data, "pay:"):
+		b.handleGuidedPayment(ctx, cq)
+		return
+	case strings.HasPrefix(data, "page:"):
+		b.handleGuidedCategoryPage(ctx, cq)
+		return
+	case strings.HasPrefix(data, "cat:"):
+		b.handleGuidedCategory(ctx, cq)
+		return
+	case strings.HasPrefix(data, "confirm:"):
+		b.handleGuidedConfirm(ctx, cq)
+		return
+	case strings.HasPrefix(data, "cancel:"):
+		b.handleGuidedCancel(ctx, cq)
+		return
+	case strings.HasPrefix(data, "receipt_pay:"):
+		b.handleReceiptPayment(ctx, cq)
+		return
+	case strings.HasPrefix(data, "confirm_receipt:"), strings.HasPrefix(data, "cancel_receipt:"):
+		b.handleReceiptConfirmation(ctx, cq)
+		return
+	}
+
 	var lang string
 	switch data {
 	case "help_ru":
@@ -274,8 +350,13 @@ func (b *Bot) handleCallbackQuery(cq *tgbotapi.CallbackQuery) {
 		log.Printf("Failed to send help message: %v", err)
 	}
 
-	callback := tgbotapi.NewCallback(cq.ID, "")
-	if _, err := b.api.Request(callback); err != nil {
+	b.answerCallback(cq)
+}
+
+// answerCallback clears the loading spinner Telegram shows on the tapped
+// button once its handler has done its work.
+func (b *Bot) answerCallback(cq *tgbotapi.CallbackQuery) {
+	if _, err := b.api.Request(tgbotapi.NewCallback(cq.ID, "")); err != nil {
 		log.Printf("Failed to answer callback query: %v", err)
 	}
 }
@@ -294,11 +375,17 @@ func (b *Bot) sendMessage(chatID int64, text string) {
 	}
 }
 
-// /add
+// /add, or /add <amount> <card|cash> <category>. With no arguments at all,
+// starts the guided flow instead so the strict grammar isn't required.
 func (b *Bot) handleAddCommand(ctx context.Context, message *tgbotapi.Message, lang string) {
 	args := message.CommandArguments()
 	fields := strings.Fields(args)
 
+	if len(fields) == 0 {
+		b.startGuidedAdd(message, lang)
+		return
+	}
+
 	if len(fields) < 3 {
 		b.sendMessage(message.Chat.ID, b.messages[lang]["add_usage"])
 		return
@@ -317,20 +404,90 @@ func (b *Bot) handleAddCommand(ctx context.Context, message *tgbotapi.Message, l
 		return
 	}
 
-	sheetName := b.getSheetName(lang)
-	if err := b.sheetsClient.recordExpense(ctx, b.config, sheetName, category, amount, isCard, lang, b.messages); err != nil {
+	b.finalizeExpense(ctx, message.Chat.ID, message.From.ID, lang, amount, category, isCard)
+}
+
+// finalizeExpense writes an already-validated expense to Sheets, records it
+// in the history store and replies to the user. Shared by the strict /add
+// grammar, the free-text and receipt-ingestion paths, and the recurring
+// expense scheduler (which has no incoming tgbotapi.Message to read from).
+// If Sheets is unreachable even after withRetry's backoff, the expense is
+// queued in the outbox instead of being lost, and the user is told it will
+// sync once the API recovers.
+func (b *Bot) finalizeExpense(ctx context.Context, chatID, userID int64, lang string, amount float64, category string, isCard bool) {
+	sheetsClient, userConfig, err := b.resolveSheetsClient(ctx, userID)
+	if err != nil {
+		b.sendMessage(chatID, b.messages[lang]["not_connected"])
+		return
+	}
+
+	reply, err := b.writeExpense(ctx, sheetsClient, userConfig, userID, lang, amount, category, isCard)
+	if err != nil {
+		if errors.Is(err, ErrSheetsUnavailable) {
+			b.queueOfflineExpense(chatID, userID, lang, amount, category, isCard, err)
+			return
+		}
 		log.Printf("Error recording expense: %v", err)
-		b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["error_occurred"], err))
+		b.sendMessage(chatID, fmt.Sprintf(b.messages[lang]["error_occurred"], err))
 		return
 	}
 
+	b.sendMessage(chatID, reply)
+}
+
+// writeExpense records amount/category on sheetsClient and a matching
+// history entry, returning the confirmation text to show the user. Used
+// directly by finalizeExpense and again by drainOutbox once a queued
+// expense's write finally succeeds.
+func (b *Bot) writeExpense(ctx context.Context, sheetsClient *SheetsClient, userConfig Config, userID int64, lang string, amount float64, category string, isCard bool) (string, error) {
+	sheetName := b.getSheetName(lang)
+	record, err := sheetsClient.recordExpense(ctx, userConfig, sheetName, category, amount, isCard, lang, b.messages, b.categoryRowCache)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := b.history.Record(Transaction{
+		UserID:        userID,
+		Timestamp:     time.Now(),
+		Amount:        amount,
+		Category:      category,
+		IsCard:        isCard,
+		SheetName:     record.SheetName,
+		DailyCell:     record.DailyCell,
+		CategoryCell:  record.CategoryCell,
+		FormattedTerm: record.FormattedTerm,
+	}); err != nil {
+		log.Printf("Error recording history: %v", err)
+	}
+
 	paymentMethod := b.messages[lang]["payment_cash"]
 	if isCard {
 		paymentMethod = b.messages[lang]["payment_card"]
 	}
 
-	reply := fmt.Sprintf(b.messages[lang]["expense_added"], amount, category, paymentMethod)
-	b.sendMessage(message.Chat.ID, reply)
+	return fmt.Sprintf(b.messages[lang]["expense_added"], amount, category, paymentMethod), nil
+}
+
+// queueOfflineExpense durably queues an expense the user has already been
+// shown a write attempt for, so it can be retried by drainOutbox once
+// Sheets is reachable again.
+func (b *Bot) queueOfflineExpense(chatID, userID int64, lang string, amount float64, category string, isCard bool, cause error) {
+	_, err := b.outbox.Enqueue(PendingExpense{
+		UserID:    userID,
+		ChatID:    chatID,
+		Lang:      lang,
+		Amount:    amount,
+		Category:  category,
+		IsCard:    isCard,
+		LastError: cause.Error(),
+	})
+	if err != nil {
+		log.Printf("Error queuing offline expense: %v", err)
+		b.sendMessage(chatID, fmt.Sprintf(b.messages[lang]["error_occurred"], cause))
+		return
+	}
+
+	b.sendMessage(chatID, b.messages[lang]["expense_queued"])
 }
 
 // parse the payment method from the suffix - card or cash
@@ -347,8 +504,14 @@ func (b *Bot) parsePaymentMethod(suffix, lang string) (bool, error) {
 
 // /budget
 func (b *Bot) handleBudgetCommand(ctx context.Context, message *tgbotapi.Message, lang string) {
+	sheetsClient, userConfig, err := b.resolveSheetsClient(ctx, message.From.ID)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["not_connected"])
+		return
+	}
+
 	sheetName := b.getSheetName(lang)
-	budget, err := b.sheetsClient.getDailyBudget(ctx, b.config, sheetName)
+	budget, err := sheetsClient.getDailyBudget(ctx, userConfig, sheetName)
 	if err != nil {
 		log.Printf("Error getting daily budget: %v", err)
 		b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["error_occurred"], err))
@@ -395,58 +558,48 @@ func (b *Bot) handleHelpCommand(message *tgbotapi.Message) {
 	}
 }
 
-// write data to sheet
-func (sc *SheetsClient) recordExpense(ctx context.Context, config Config, sheetName, category string, amount float64, isCard bool, lang string, messages Messages) error {
-	if err := sc.writeExpenseToDailyCell(ctx, config, sheetName, amount, isCard); err != nil {
-		return fmt.Errorf("failed to write to daily cell: %w", err)
-	}
-
-	if err := sc.writeExpenseToCategoryCell(ctx, config, sheetName, category, amount, isCard, lang, messages); err != nil {
-		return fmt.Errorf("failed to write to category cell: %w", err)
-	}
-
-	return nil
+// ExpenseRecord identifies exactly where a recorded expense landed, so the
+// history store can later locate and reconcile the same formula term.
+type ExpenseRecord struct {
+	SheetName     string
+	DailyCell     string
+	CategoryCell  string
+	FormattedTerm string
 }
 
-func (sc *SheetsClient) writeExpenseToDailyCell(ctx context.Context, config Config, sheetName string, amount float64, isCard bool) error {
-	now := time.Now()
-	row := now.Day() + 1
-	cell := fmt.Sprintf("%s!I%d", sheetName, row)
-
-	currentValue, err := sc.getCellValue(ctx, config.SpreadsheetID, cell, "FORMULA")
-	if err != nil {
-		return err
-	}
-
-	formattedAmount := sc.formatAmount(amount, isCard)
-	newValue := sc.buildNewFormula(currentValue, formattedAmount)
-
-	return sc.updateCellValue(ctx, config.SpreadsheetID, cell, newValue)
-}
+// write data to sheet
+//
+// recordExpense itself lives in batchwriter.go, batching the daily and
+// category cell reads/writes into one batchGet and one batchUpdate call.
 
-func (sc *SheetsClient) writeExpenseToCategoryCell(ctx context.Context, config Config, sheetName, category string, amount float64, isCard bool, lang string, messages Messages) error {
+func (sc *SheetsClient) writeExpenseToCategoryCell(ctx context.Context, config Config, sheetName, category string, amount float64, isCard bool, lang string, messages Messages) (string, error) {
 	categoryRange := fmt.Sprintf("'%s'!%s", sheetName, config.CellRanges.CategoryRange)
-	resp, err := sc.service.Spreadsheets.Values.Get(config.SpreadsheetID, categoryRange).Do()
+	var resp *sheets.ValueRange
+	err := withRetry(ctx, func() error {
+		var err error
+		resp, err = sc.service.Spreadsheets.Values.Get(config.SpreadsheetID, categoryRange).Context(ctx).Do()
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get category range: %w", err)
+		return "", fmt.Errorf("failed to get category range: %w", err)
 	}
 
 	rowIndex, found := sc.findCategoryRow(resp.Values, category)
 	if !found {
-		return fmt.Errorf(messages[lang]["category_not_found"], category)
+		return "", fmt.Errorf(messages[lang]["category_not_found"], category)
 	}
 
 	row := 22 + rowIndex
 	cell := fmt.Sprintf("%s!%s%d", sheetName, config.CellRanges.CategoryColumn, row)
 	currentValue, err := sc.getCellValue(ctx, config.SpreadsheetID, cell, "FORMULA")
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	formattedAmount := sc.formatAmount(amount, isCard)
 	newValue := sc.buildNewFormula(currentValue, formattedAmount)
 
-	return sc.updateCellValue(ctx, config.SpreadsheetID, cell, newValue)
+	return cell, sc.updateCellValue(ctx, config.SpreadsheetID, cell, newValue)
 }
 
 func (sc *SheetsClient) getDailyBudget(ctx context.Context, config Config, sheetName string) (string, error) {
@@ -462,7 +615,12 @@ func (sc *SheetsClient) getDailyBudget(ctx context.Context, config Config, sheet
 }
 
 func (sc *SheetsClient) getCellValue(ctx context.Context, spreadsheetID, cell, valueRenderOption string) (string, error) {
-	resp, err := sc.service.Spreadsheets.Values.Get(spreadsheetID, cell).ValueRenderOption(valueRenderOption).Context(ctx).Do()
+	var resp *sheets.ValueRange
+	err := withRetry(ctx, func() error {
+		var err error
+		resp, err = sc.service.Spreadsheets.Values.Get(spreadsheetID, cell).ValueRenderOption(valueRenderOption).Context(ctx).Do()
+		return err
+	})
 	if err != nil {
 		return "", fmt.Errorf("failed to get cell %s: %w", cell, err)
 	}
@@ -483,10 +641,13 @@ func (sc *SheetsClient) updateCellValue(ctx context.Context, spreadsheetID, cell
 		Values: [][]interface{}{{value}},
 	}
 
-	_, err := sc.service.Spreadsheets.Values.Update(spreadsheetID, cell, valueRange).
-		ValueInputOption("USER_ENTERED").
-		Context(ctx).
-		Do()
+	err := withRetry(ctx, func() error {
+		_, err := sc.service.Spreadsheets.Values.Update(spreadsheetID, cell, valueRange).
+			ValueInputOption("USER_ENTERED").
+			Context(ctx).
+			Do()
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to update cell %s: %w", cell, err)
 	}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// resolveSheetsClient looks up telegramUserID's connected spreadsheet and
+// returns a SheetsClient authorized as them, plus the Config to pass to its
+// methods (SpreadsheetID and CellRanges filled in from the user's record).
+// The OAuth token source is cached per user and refreshes automatically;
+// refreshed tokens are persisted back to UserStore.
+func (b *Bot) resolveSheetsClient(ctx context.Context, telegramUserID int64) (*SheetsClient, Config, error) {
+	record, err := b.userStore.Get(telegramUserID)
+	if err != nil {
+		return nil, Config{}, fmt.Errorf("user %d has not connected a spreadsheet: %w", telegramUserID, err)
+	}
+
+	tokenSource, err := b.tokenSourceForUser(ctx, telegramUserID, record)
+	if err != nil {
+		return nil, Config{}, err
+	}
+
+	service, err := sheets.NewService(ctx, option.WithTokenSource(tokenSource))
+	if err != nil {
+		return nil, Config{}, fmt.Errorf("failed to create Sheets service for user %d: %w", telegramUserID, err)
+	}
+
+	userConfig := b.config
+	userConfig.SpreadsheetID = record.SpreadsheetID
+	if record.CellRanges != nil {
+		userConfig.CellRanges = *record.CellRanges
+	}
+
+	return &SheetsClient{service: service}, userConfig, nil
+}
+
+// tokenSourceForUser returns the cached oauth2.TokenSource for telegramUserID,
+// creating and caching one from the user's stored token if needed.
+func (b *Bot) tokenSourceForUser(ctx context.Context, telegramUserID int64, record *UserRecord) (oauth2.TokenSource, error) {
+	b.tokenSourcesMu.Lock()
+	defer b.tokenSourcesMu.Unlock()
+
+	if ts, ok := b.tokenSources[telegramUserID]; ok {
+		return ts, nil
+	}
+
+	token, err := b.decryptOAuthToken(record.EncryptedToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt token for user %d: %w", telegramUserID, err)
+	}
+
+	base := b.oauthConfig.TokenSource(ctx, token)
+	persisting := &persistingTokenSource{
+		bot:     b,
+		userID:  telegramUserID,
+		base:    base,
+		lastRaw: token.AccessToken,
+	}
+	ts := oauth2.ReuseTokenSource(token, persisting)
+
+	b.tokenSources[telegramUserID] = ts
+	return ts, nil
+}
+
+// invalidateTokenSource drops telegramUserID's cached TokenSource, if any,
+// so the next resolveSheetsClient call rebuilds one from the freshly saved
+// token instead of keeping a stale source alive after /connect re-authorizes.
+func (b *Bot) invalidateTokenSource(telegramUserID int64) {
+	b.tokenSourcesMu.Lock()
+	delete(b.tokenSources, telegramUserID)
+	b.tokenSourcesMu.Unlock()
+}
+
+// persistingTokenSource wraps a user's base TokenSource so that whenever the
+// underlying library refreshes the access token, the new one is encrypted
+// and written back to UserStore.
+type persistingTokenSource struct {
+	bot     *Bot
+	userID  int64
+	base    oauth2.TokenSource
+	lastRaw string
+}
+
+func (p *persistingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := p.base.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token for user %d: %w", p.userID, err)
+	}
+
+	if token.AccessToken != p.lastRaw {
+		encrypted, err := p.bot.encryptOAuthToken(token)
+		if err == nil {
+			if err := p.bot.userStore.SaveToken(p.userID, encrypted); err != nil {
+				return token, nil // serve the fresh token even if persisting it failed
+			}
+		}
+		p.lastRaw = token.AccessToken
+	}
+
+	return token, nil
+}
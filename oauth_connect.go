@@ -0,0 +1,171 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/sheets/v4"
+)
+
+// defaultOAuthCallbackPort is used when Config.OAuth.CallbackPort is unset.
+const defaultOAuthCallbackPort = 8085
+
+var startCallbackServerOnce sync.Once
+
+// loadOAuthConfig builds the shared Google OAuth client config every tenant
+// authorizes against; only the resulting token differs per user.
+func loadOAuthConfig(cfg OAuthConfig) (*oauth2.Config, error) {
+	credentialsPath := cfg.CredentialsPath
+	if credentialsPath == "" {
+		credentialsPath = "credentials.json"
+	}
+
+	creds, err := os.ReadFile(credentialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", credentialsPath, err)
+	}
+
+	oauthConfig, err := google.ConfigFromJSON(creds, sheets.SpreadsheetsScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Google OAuth config: %w", err)
+	}
+
+	port := cfg.CallbackPort
+	if port == 0 {
+		port = defaultOAuthCallbackPort
+	}
+	host := cfg.CallbackHost
+	if host == "" {
+		host = fmt.Sprintf("http://localhost:%d", port)
+	}
+	oauthConfig.RedirectURL = strings.TrimRight(host, "/") + "/oauth2callback"
+
+	return oauthConfig, nil
+}
+
+// /connect <spreadsheet_id>
+func (b *Bot) handleConnectCommand(message *tgbotapi.Message, lang string) {
+	spreadsheetID := strings.TrimSpace(message.CommandArguments())
+	if spreadsheetID == "" {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["connect_usage"])
+		return
+	}
+
+	state, err := generateOAuthState()
+	if err != nil {
+		log.Printf("Error generating OAuth state: %v", err)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["error_occurred"], err))
+		return
+	}
+
+	if err := b.userStore.PutPendingState(state, message.From.ID, spreadsheetID); err != nil {
+		log.Printf("Error storing pending OAuth state: %v", err)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["error_occurred"], err))
+		return
+	}
+
+	b.startOAuthCallbackServer()
+
+	authURL := b.oauthConfig.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce)
+	b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["connect_prompt"], authURL))
+}
+
+// startOAuthCallbackServer starts the HTTP server that completes /connect
+// exactly once per process, regardless of how many users run /connect.
+func (b *Bot) startOAuthCallbackServer() {
+	startCallbackServerOnce.Do(func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/oauth2callback", b.handleOAuthCallback)
+
+		port := b.config.OAuth.CallbackPort
+		if port == 0 {
+			port = defaultOAuthCallbackPort
+		}
+
+		go func() {
+			addr := fmt.Sprintf(":%d", port)
+			if err := http.ListenAndServe(addr, mux); err != nil {
+				log.Printf("OAuth callback server stopped: %v", err)
+			}
+		}()
+	})
+}
+
+func (b *Bot) handleOAuthCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	state := r.URL.Query().Get("state")
+	code := r.URL.Query().Get("code")
+
+	userID, spreadsheetID, err := b.userStore.ConsumePendingState(state)
+	if err != nil {
+		http.Error(w, "connection request not found or expired, please run /connect again", http.StatusBadRequest)
+		return
+	}
+
+	token, err := b.oauthConfig.Exchange(ctx, code)
+	if err != nil {
+		http.Error(w, "failed to exchange authorization code", http.StatusBadGateway)
+		log.Printf("Error exchanging OAuth code for user %d: %v", userID, err)
+		return
+	}
+
+	encryptedToken, err := b.encryptOAuthToken(token)
+	if err != nil {
+		http.Error(w, "failed to store token", http.StatusInternalServerError)
+		log.Printf("Error encrypting token for user %d: %v", userID, err)
+		return
+	}
+
+	if err := b.userStore.Save(UserRecord{
+		TelegramUserID: userID,
+		SpreadsheetID:  spreadsheetID,
+		EncryptedToken: encryptedToken,
+		Language:       b.getUserLanguage(userID),
+	}); err != nil {
+		http.Error(w, "failed to store connection", http.StatusInternalServerError)
+		log.Printf("Error saving user %d: %v", userID, err)
+		return
+	}
+	b.invalidateTokenSource(userID)
+
+	fmt.Fprint(w, "Your spreadsheet is connected. You can return to Telegram now.")
+}
+
+func (b *Bot) encryptOAuthToken(token *oauth2.Token) ([]byte, error) {
+	plaintext, err := json.Marshal(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode token: %w", err)
+	}
+	return encryptToken(b.tokenCipher, plaintext)
+}
+
+func (b *Bot) decryptOAuthToken(encrypted []byte) (*oauth2.Token, error) {
+	plaintext, err := decryptToken(b.tokenCipher, encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt token: %w", err)
+	}
+
+	var token oauth2.Token
+	if err := json.Unmarshal(plaintext, &token); err != nil {
+		return nil, fmt.Errorf("failed to decode token: %w", err)
+	}
+	return &token, nil
+}
+
+func generateOAuthState() (string, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate random state: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// PendingExpense is an expense the bot has already acknowledged to the user
+// but couldn't yet write to Sheets, because resolveSheetsClient or the write
+// itself failed after withRetry gave up.
+type PendingExpense struct {
+	ID        int64
+	UserID    int64
+	ChatID    int64
+	Lang      string
+	Amount    float64
+	Category  string
+	IsCard    bool
+	QueuedAt  time.Time
+	LastError string
+}
+
+// OutboxStore durably queues expenses that couldn't reach Sheets, so they
+// survive a bot restart and can be drained once the API is reachable again.
+type OutboxStore struct {
+	db *sql.DB
+}
+
+func NewOutboxStore(path string) (*OutboxStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbox store: %w", err)
+	}
+
+	const schema = `
+	CREATE TABLE IF NOT EXISTS outbox (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id    INTEGER NOT NULL,
+		chat_id    INTEGER NOT NULL,
+		lang       TEXT NOT NULL,
+		amount     REAL NOT NULL,
+		category   TEXT NOT NULL,
+		is_card    INTEGER NOT NULL,
+		queued_at  DATETIME NOT NULL,
+		last_error TEXT NOT NULL DEFAULT ''
+	);`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to init outbox schema: %w", err)
+	}
+
+	return &OutboxStore{db: db}, nil
+}
+
+// Enqueue records an expense the bot acknowledged but couldn't yet write.
+func (os *OutboxStore) Enqueue(pe PendingExpense) (int64, error) {
+	res, err := os.db.Exec(
+		`INSERT INTO outbox (user_id, chat_id, lang, amount, category, is_card, queued_at, last_error)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		pe.UserID, pe.ChatID, pe.Lang, pe.Amount, pe.Category, pe.IsCard, time.Now(), pe.LastError,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue pending expense: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// All returns every queued expense, oldest first.
+func (os *OutboxStore) All() ([]PendingExpense, error) {
+	rows, err := os.db.Query(
+		`SELECT id, user_id, chat_id, lang, amount, category, is_card, queued_at, last_error FROM outbox ORDER BY queued_at ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var all []PendingExpense
+	for rows.Next() {
+		var pe PendingExpense
+		var isCard int
+		if err := rows.Scan(&pe.ID, &pe.UserID, &pe.ChatID, &pe.Lang, &pe.Amount, &pe.Category, &isCard, &pe.QueuedAt, &pe.LastError); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox row: %w", err)
+		}
+		pe.IsCard = isCard != 0
+		all = append(all, pe)
+	}
+	return all, rows.Err()
+}
+
+// ForUser returns userID's queued expenses, oldest first, for /pending.
+func (os *OutboxStore) ForUser(userID int64) ([]PendingExpense, error) {
+	all, err := os.All()
+	if err != nil {
+		return nil, err
+	}
+
+	var mine []PendingExpense
+	for _, pe := range all {
+		if pe.UserID == userID {
+			mine = append(mine, pe)
+		}
+	}
+	return mine, nil
+}
+
+// MarkFailed records the latest error for a queued expense still awaiting a
+// successful drain, so /pending can show why it hasn't gone through yet.
+func (os *OutboxStore) MarkFailed(id int64, lastError string) error {
+	_, err := os.db.Exec(`UPDATE outbox SET last_error = ? WHERE id = ?`, lastError, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox entry %d failed: %w", id, err)
+	}
+	return nil
+}
+
+// Remove deletes a queued expense once it has been successfully written.
+func (os *OutboxStore) Remove(id int64) error {
+	_, err := os.db.Exec(`DELETE FROM outbox WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to remove outbox entry %d: %w", id, err)
+	}
+	return nil
+}
+
+func (os *OutboxStore) Close() error {
+	return os.db.Close()
+}
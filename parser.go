@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// confidenceThreshold is the minimum confidence a Parser must report before
+// an expense is written to Sheets without asking the user to confirm it.
+const confidenceThreshold = 0.75
+
+// ParsedExpense is what a Parser extracts from a free-text message such as
+// "spent 12.50 on coffee with card".
+type ParsedExpense struct {
+	Amount        float64
+	Currency      string
+	Category      string
+	PaymentMethod string // "card" or "cash"
+	Confidence    float64
+}
+
+// Parser turns free-form natural language into a structured expense.
+type Parser interface {
+	Parse(ctx context.Context, text string) (ParsedExpense, error)
+}
+
+// ParserConfig selects and configures the natural-language parser.
+type ParserConfig struct {
+	Provider string `json:"provider"` // "regex", "openai", "anthropic" or "ollama"
+	Model    string `json:"model"`
+	// EmbeddingModel is the model resolveCategory's nearest-match embeds
+	// with; independent of Model, which is the chat/tool-calling model and
+	// rejected by OpenAI's /embeddings endpoint.
+	EmbeddingModel string `json:"embedding_model"`
+	APIKey         string `json:"api_key"`
+	BaseURL        string `json:"base_url"`
+}
+
+// NewParser builds the Parser selected by cfg.Provider.
+func NewParser(cfg ParserConfig) (Parser, error) {
+	switch cfg.Provider {
+	case "", "regex":
+		return NewRegexParser(), nil
+	case "openai", "anthropic", "ollama":
+		client, err := newLLMClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return NewLLMParser(client), nil
+	default:
+		return nil, fmt.Errorf("unknown parser provider %q", cfg.Provider)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// llmClient sends a single prompt to a function-calling capable model and
+// returns the raw JSON arguments the model produced for the expense tool.
+type llmClient interface {
+	CompleteJSON(ctx context.Context, prompt string) (string, error)
+}
+
+// newLLMClient builds the llmClient for cfg.Provider.
+func newLLMClient(cfg ParserConfig) (llmClient, error) {
+	switch cfg.Provider {
+	case "openai":
+		return &openAIClient{apiKey: cfg.APIKey, model: cfg.Model, baseURL: defaultString(cfg.BaseURL, "https://api.openai.com/v1")}, nil
+	case "anthropic":
+		return &anthropicClient{apiKey: cfg.APIKey, model: cfg.Model, baseURL: defaultString(cfg.BaseURL, "https://api.anthropic.com/v1")}, nil
+	case "ollama":
+		return &ollamaClient{model: cfg.Model, baseURL: defaultString(cfg.BaseURL, "http://localhost:11434")}, nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", cfg.Provider)
+	}
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// extractExpenseTool is the JSON schema shared by every provider's
+// function-calling request: it forces the model to return exactly the
+// fields ParsedExpense needs.
+const extractExpenseSchema = `{
+	"name": "extract_expense",
+	"description": "Extract a structured expense from a free-text message",
+	"parameters": {
+		"type": "object",
+		"properties": {
+			"amount": {"type": "number"},
+			"currency": {"type": "string"},
+			"category": {"type": "string"},
+			"payment_method": {"type": "string", "enum": ["card", "cash"]},
+			"confidence": {"type": "number"}
+		},
+		"required": ["amount", "category", "payment_method", "confidence"]
+	}
+}`
+
+// LLMParser delegates extraction to a function-calling LLM and maps the
+// free-form category it returns onto the nearest row in CategoryRange.
+type LLMParser struct {
+	client llmClient
+}
+
+func NewLLMParser(client llmClient) *LLMParser {
+	return &LLMParser{client: client}
+}
+
+func (p *LLMParser) Parse(ctx context.Context, text string) (ParsedExpense, error) {
+	raw, err := p.client.CompleteJSON(ctx, text)
+	if err != nil {
+		return ParsedExpense{}, fmt.Errorf("failed to call LLM parser: %w", err)
+	}
+
+	var parsed struct {
+		Amount        float64 `json:"amount"`
+		Currency      string  `json:"currency"`
+		Category      string  `json:"category"`
+		PaymentMethod string  `json:"payment_method"`
+		Confidence    float64 `json:"confidence"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return ParsedExpense{}, fmt.Errorf("failed to parse LLM response %q: %w", raw, err)
+	}
+
+	return ParsedExpense{
+		Amount:        parsed.Amount,
+		Currency:      parsed.Currency,
+		Category:      parsed.Category,
+		PaymentMethod: parsed.PaymentMethod,
+		Confidence:    parsed.Confidence,
+	}, nil
+}
+
+// openAIClient calls OpenAI's chat completions API with a forced tool call.
+type openAIClient struct {
+	apiKey  string
+	model   string
+	baseURL string
+}
+
+func (c *openAIClient) CompleteJSON(ctx context.Context, prompt string) (string, error) {
+	body := map[string]interface{}{
+		"model": c.model,
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+		"tools":       []json.RawMessage{[]byte(`{"type":"function","function":` + extractExpenseSchema + `}`)},
+		"tool_choice": map[string]interface{}{"type": "function", "function": map[string]string{"name": "extract_expense"}},
+	}
+
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				ToolCalls []struct {
+					Function struct {
+						Arguments string `json:"arguments"`
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := postJSON(ctx, c.baseURL+"/chat/completions", c.apiKey, body, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 || len(resp.Choices[0].Message.ToolCalls) == 0 {
+		return "", fmt.Errorf("openai returned no tool call")
+	}
+	return resp.Choices[0].Message.ToolCalls[0].Function.Arguments, nil
+}
+
+// anthropicClient calls the Messages API with a forced tool_use block.
+type anthropicClient struct {
+	apiKey  string
+	model   string
+	baseURL string
+}
+
+func (c *anthropicClient) CompleteJSON(ctx context.Context, prompt string) (string, error) {
+	var tool map[string]interface{}
+	if err := json.Unmarshal([]byte(extractExpenseSchema), &tool); err != nil {
+		return "", fmt.Errorf("failed to build tool schema: %w", err)
+	}
+	tool["input_schema"] = tool["parameters"]
+	delete(tool, "parameters")
+
+	body := map[string]interface{}{
+		"model":      c.model,
+		"max_tokens": 256,
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+		"tools":       []interface{}{tool},
+		"tool_choice": map[string]string{"type": "tool", "name": "extract_expense"},
+	}
+
+	var resp struct {
+		Content []struct {
+			Type  string          `json:"type"`
+			Input json.RawMessage `json:"input"`
+		} `json:"content"`
+	}
+	if err := postJSON(ctx, c.baseURL+"/messages", c.apiKey, body, &resp); err != nil {
+		return "", err
+	}
+	for _, block := range resp.Content {
+		if block.Type == "tool_use" {
+			return string(block.Input), nil
+		}
+	}
+	return "", fmt.Errorf("anthropic returned no tool_use block")
+}
+
+// ollamaClient calls a locally running Ollama model with its JSON mode.
+type ollamaClient struct {
+	model   string
+	baseURL string
+}
+
+func (c *ollamaClient) CompleteJSON(ctx context.Context, prompt string) (string, error) {
+	body := map[string]interface{}{
+		"model":  c.model,
+		"prompt": prompt + "\n\nRespond with JSON matching this schema: " + extractExpenseSchema,
+		"format": "json",
+		"stream": false,
+	}
+
+	var resp struct {
+		Response string `json:"response"`
+	}
+	if err := postJSON(ctx, c.baseURL+"/api/generate", "", body, &resp); err != nil {
+		return "", err
+	}
+	return resp.Response, nil
+}
+
+// postJSON POSTs body as JSON to url (with an optional bearer apiKey) and
+// decodes the JSON response into out.
+func postJSON(ctx context.Context, url, apiKey string, body interface{}, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+		req.Header.Set("x-api-key", apiKey)
+		req.Header.Set("anthropic-version", "2023-06-01")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response from %s: %w", url, err)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d: %s", url, resp.StatusCode, data)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", url, err)
+	}
+	return nil
+}
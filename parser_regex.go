@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// amountPattern matches the first decimal number in a free-text message.
+var amountPattern = regexp.MustCompile(`\d+([.,]\d+)?`)
+
+var cardKeywords = []string{"card", "карт", "карта", "картой"}
+var cashKeywords = []string{"cash", "наличн", "наличка"}
+
+// fillerWords are stripped from the remaining text before it is used as the category guess.
+var fillerWords = []string{"spent", "on", "with", "потратил", "потратила", "на", "картой", "наличными", "card", "cash"}
+
+// RegexParser extracts expenses from free text with a keyword/regex
+// heuristic; it needs no network access and is the zero-config default.
+type RegexParser struct{}
+
+func NewRegexParser() *RegexParser {
+	return &RegexParser{}
+}
+
+func (p *RegexParser) Parse(ctx context.Context, text string) (ParsedExpense, error) {
+	match := amountPattern.FindString(text)
+	if match == "" {
+		return ParsedExpense{}, fmt.Errorf("no amount found in %q", text)
+	}
+
+	amount, err := strconv.ParseFloat(strings.Replace(match, ",", ".", 1), 64)
+	if err != nil {
+		return ParsedExpense{}, fmt.Errorf("failed to parse amount %q: %w", match, err)
+	}
+
+	lower := strings.ToLower(text)
+	paymentMethod := ""
+	for _, kw := range cardKeywords {
+		if strings.Contains(lower, kw) {
+			paymentMethod = "card"
+			break
+		}
+	}
+	if paymentMethod == "" {
+		for _, kw := range cashKeywords {
+			if strings.Contains(lower, kw) {
+				paymentMethod = "cash"
+				break
+			}
+		}
+	}
+
+	category := extractCategory(text, match)
+
+	confidence := 0.4 // heuristics are never very sure
+	if paymentMethod != "" {
+		confidence += 0.2
+	}
+	if category != "" {
+		confidence += 0.2
+	}
+
+	return ParsedExpense{
+		Amount:        amount,
+		Category:      category,
+		PaymentMethod: paymentMethod,
+		Confidence:    confidence,
+	}, nil
+}
+
+// extractCategory takes the words left over once the amount and known
+// filler words are removed, e.g. "spent 12.50 on coffee with card" -> "coffee".
+func extractCategory(text, amountMatch string) string {
+	withoutAmount := strings.Replace(text, amountMatch, "", 1)
+	words := strings.Fields(withoutAmount)
+
+	var kept []string
+	for _, word := range words {
+		clean := strings.ToLower(strings.Trim(word, ".,!?"))
+		skip := false
+		for _, filler := range fillerWords {
+			if clean == filler {
+				skip = true
+				break
+			}
+		}
+		if !skip && clean != "" {
+			kept = append(kept, clean)
+		}
+	}
+	return strings.Join(kept, " ")
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// /pending lists the user's expenses still sitting in the offline outbox,
+// queued because Sheets was unreachable when they were added.
+func (b *Bot) handlePendingCommand(message *tgbotapi.Message, lang string) {
+	items, err := b.outbox.ForUser(message.From.ID)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["error_occurred"], err))
+		return
+	}
+
+	if len(items) == 0 {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["pending_empty"])
+		return
+	}
+
+	lines := make([]string, 0, len(items))
+	for _, pe := range items {
+		paymentMethod := b.messages[lang]["payment_cash"]
+		if pe.IsCard {
+			paymentMethod = b.messages[lang]["payment_card"]
+		}
+		lines = append(lines, fmt.Sprintf(
+			b.messages[lang]["pending_entry"],
+			pe.ID, pe.Amount, pe.Category, paymentMethod, pe.QueuedAt.Format("2006-01-02 15:04"),
+		))
+	}
+
+	b.sendMessage(message.Chat.ID, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// UserPreferences holds the per-user settings the reminder scheduler needs:
+// a timezone to evaluate "local time" in, and an optional daily nudge.
+type UserPreferences struct {
+	UserID          int64
+	ChatID          int64
+	Timezone        string
+	ReminderTime    string // "HH:MM", local to Timezone
+	ReminderEnabled bool
+	LastReminded    string // "YYYY-MM-DD" the reminder last fired on, to avoid double-sends
+}
+
+// PreferencesStore persists /tz and /reminders settings.
+type PreferencesStore struct {
+	db *sql.DB
+}
+
+func NewPreferencesStore(path string) (*PreferencesStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open preferences store: %w", err)
+	}
+
+	const schema = `
+	CREATE TABLE IF NOT EXISTS user_preferences (
+		user_id          INTEGER PRIMARY KEY,
+		chat_id          INTEGER NOT NULL,
+		timezone         TEXT NOT NULL DEFAULT 'UTC',
+		reminder_time    TEXT NOT NULL DEFAULT '',
+		reminder_enabled INTEGER NOT NULL DEFAULT 0,
+		last_reminded    TEXT NOT NULL DEFAULT ''
+	);`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to init preferences schema: %w", err)
+	}
+
+	return &PreferencesStore{db: db}, nil
+}
+
+// Get returns userID's preferences, or sensible zero-value defaults (UTC,
+// reminders disabled) if they have never set any.
+func (ps *PreferencesStore) Get(userID int64) (UserPreferences, error) {
+	var prefs UserPreferences
+	row := ps.db.QueryRow(
+		`SELECT user_id, chat_id, timezone, reminder_time, reminder_enabled, last_reminded FROM user_preferences WHERE user_id = ?`,
+		userID,
+	)
+	err := row.Scan(&prefs.UserID, &prefs.ChatID, &prefs.Timezone, &prefs.ReminderTime, &prefs.ReminderEnabled, &prefs.LastReminded)
+	if err == sql.ErrNoRows {
+		return UserPreferences{UserID: userID}, nil
+	}
+	if err != nil {
+		return UserPreferences{}, fmt.Errorf("failed to get preferences for user %d: %w", userID, err)
+	}
+	return prefs, nil
+}
+
+// SetTimezone upserts a user's timezone, used by /tz.
+func (ps *PreferencesStore) SetTimezone(userID, chatID int64, timezone string) error {
+	_, err := ps.db.Exec(
+		`INSERT INTO user_preferences (user_id, chat_id, timezone) VALUES (?, ?, ?)
+		 ON CONFLICT(user_id) DO UPDATE SET timezone = excluded.timezone, chat_id = excluded.chat_id`,
+		userID, chatID, timezone,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set timezone for user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// SetReminder upserts a user's daily reminder time and enables it, used by /reminders.
+func (ps *PreferencesStore) SetReminder(userID, chatID int64, reminderTime string) error {
+	_, err := ps.db.Exec(
+		`INSERT INTO user_preferences (user_id, chat_id, reminder_time, reminder_enabled) VALUES (?, ?, ?, 1)
+		 ON CONFLICT(user_id) DO UPDATE SET reminder_time = excluded.reminder_time, reminder_enabled = 1, chat_id = excluded.chat_id`,
+		userID, chatID, reminderTime,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set reminder for user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// DisableReminder turns off a user's daily nudge, used by "/reminders off".
+func (ps *PreferencesStore) DisableReminder(userID int64) error {
+	_, err := ps.db.Exec(`UPDATE user_preferences SET reminder_enabled = 0 WHERE user_id = ?`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to disable reminder for user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// ListReminderEnabled returns every user with a daily reminder configured.
+func (ps *PreferencesStore) ListReminderEnabled() ([]UserPreferences, error) {
+	rows, err := ps.db.Query(
+		`SELECT user_id, chat_id, timezone, reminder_time, reminder_enabled, last_reminded FROM user_preferences WHERE reminder_enabled = 1`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reminder-enabled users: %w", err)
+	}
+	defer rows.Close()
+
+	var all []UserPreferences
+	for rows.Next() {
+		var prefs UserPreferences
+		if err := rows.Scan(&prefs.UserID, &prefs.ChatID, &prefs.Timezone, &prefs.ReminderTime, &prefs.ReminderEnabled, &prefs.LastReminded); err != nil {
+			return nil, fmt.Errorf("failed to scan preferences: %w", err)
+		}
+		all = append(all, prefs)
+	}
+	return all, rows.Err()
+}
+
+// MarkReminded records that today's nudge was sent, so the catch-up sweep
+// on the next tick doesn't send it twice.
+func (ps *PreferencesStore) MarkReminded(userID int64, date string) error {
+	_, err := ps.db.Exec(`UPDATE user_preferences SET last_reminded = ? WHERE user_id = ?`, date, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark reminder sent for user %d: %w", userID, err)
+	}
+	return nil
+}
+
+func (ps *PreferencesStore) Close() error {
+	return ps.db.Close()
+}
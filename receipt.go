@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// pendingReceipt is a photographed receipt awaiting the user's payment
+// method and final confirmation before anything is written to Sheets.
+type pendingReceipt struct {
+	chatID int64
+	userID int64
+	lang   string
+	fileID string
+	items  []ReceiptLineItem
+	total  float64
+	isCard bool
+}
+
+// receiptSessions holds each user's in-progress receipt review. Unlike the
+// guided /add FSM, a receipt review is short-lived and not worth persisting
+// across a restart, so it's a plain mutex-guarded map.
+type receiptSessions struct {
+	mu       sync.Mutex
+	sessions map[int64]pendingReceipt
+}
+
+func newReceiptSessions() *receiptSessions {
+	return &receiptSessions{sessions: make(map[int64]pendingReceipt)}
+}
+
+func (rs *receiptSessions) get(userID int64) (pendingReceipt, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	pr, ok := rs.sessions[userID]
+	return pr, ok
+}
+
+func (rs *receiptSessions) set(pr pendingReceipt) {
+	rs.mu.Lock()
+	rs.sessions[pr.userID] = pr
+	rs.mu.Unlock()
+}
+
+func (rs *receiptSessions) delete(userID int64) {
+	rs.mu.Lock()
+	delete(rs.sessions, userID)
+	rs.mu.Unlock()
+}
+
+// handleReceiptPhoto downloads a photographed or scanned receipt, runs it
+// through the configured ReceiptExtractor, and asks the user for a payment
+// method before anything is written to Sheets.
+func (b *Bot) handleReceiptPhoto(ctx context.Context, message *tgbotapi.Message, lang string) {
+	fileID, mimeType := receiptFileID(message)
+	if fileID == "" {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["receipt_unsupported"])
+		return
+	}
+
+	image, err := b.downloadTelegramFile(fileID)
+	if err != nil {
+		log.Printf("Error downloading receipt: %v", err)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["error_occurred"], err))
+		return
+	}
+
+	extracted, err := b.receiptExtractor.Extract(ctx, image, mimeType)
+	if err != nil || len(extracted.LineItems) == 0 {
+		log.Printf("Error extracting receipt: %v", err)
+		b.sendMessage(message.Chat.ID, b.messages[lang]["receipt_extraction_failed"])
+		return
+	}
+
+	for i, item := range extracted.LineItems {
+		extracted.LineItems[i].Category = b.resolveCategory(ctx, message.From.ID, lang, item.Category)
+	}
+
+	b.receiptSessions.set(pendingReceipt{
+		chatID: message.Chat.ID,
+		userID: message.From.ID,
+		lang:   lang,
+		fileID: fileID,
+		items:  extracted.LineItems,
+		total:  extracted.Total,
+	})
+
+	buttons := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.messages[lang]["payment_cash"], "receipt_pay:cash"),
+		tgbotapi.NewInlineKeyboardButtonData(b.messages[lang]["payment_card"], "receipt_pay:card"),
+	))
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["receipt_extracted"], len(extracted.LineItems), extracted.Total))
+	msg.ReplyMarkup = buttons
+	if _, err := b.api.Send(msg); err != nil {
+		log.Printf("Failed to send receipt payment prompt: %v", err)
+	}
+}
+
+// receiptFileID returns the Telegram file_id for a photo message (largest
+// size) or an image document, and its MIME type. The zero value means
+// message carries nothing handleReceiptPhoto can use.
+func receiptFileID(message *tgbotapi.Message) (fileID, mimeType string) {
+	if len(message.Photo) > 0 {
+		largest := message.Photo[len(message.Photo)-1]
+		return largest.FileID, "image/jpeg"
+	}
+	if message.Document != nil && strings.HasPrefix(message.Document.MimeType, "image/") {
+		return message.Document.FileID, message.Document.MimeType
+	}
+	return "", ""
+}
+
+// downloadTelegramFile resolves fileID to its file_path via the Bot API and
+// downloads its bytes.
+func (b *Bot) downloadTelegramFile(fileID string) ([]byte, error) {
+	file, err := b.api.GetFile(tgbotapi.FileConfig{FileID: fileID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	resp, err := http.Get(file.Link(b.api.Token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read downloaded file: %w", err)
+	}
+	return data, nil
+}
+
+// handleReceiptPayment applies the chosen payment method and shows the
+// itemized confirmation before any Sheets write happens.
+func (b *Bot) handleReceiptPayment(ctx context.Context, cq *tgbotapi.CallbackQuery) {
+	defer b.answerCallback(cq)
+
+	pending, ok := b.receiptSessions.get(cq.From.ID)
+	if !ok {
+		return
+	}
+	pending.isCard = cq.Data == "receipt_pay:card"
+	b.receiptSessions.set(pending)
+
+	var lines strings.Builder
+	for _, item := range pending.items {
+		lines.WriteString(fmt.Sprintf("%s — %.2f (%s)\n", item.Description, item.Amount, item.Category))
+	}
+
+	prompt := fmt.Sprintf(b.messages[pending.lang]["receipt_confirm_prompt"], lines.String(), pending.total)
+	buttons := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.messages[pending.lang]["confirm_yes"], "confirm_receipt:add"),
+		tgbotapi.NewInlineKeyboardButtonData(b.messages[pending.lang]["confirm_no"], "cancel_receipt:add"),
+	))
+
+	msg := tgbotapi.NewMessage(pending.chatID, prompt)
+	msg.ReplyMarkup = buttons
+	if _, err := b.api.Send(msg); err != nil {
+		log.Printf("Failed to send receipt confirmation: %v", err)
+	}
+}
+
+// handleReceiptConfirmation writes the receipt's line items across their
+// category cells in one batched Sheets write, recording each item in
+// history tagged with the original photo's file_id for auditing.
+func (b *Bot) handleReceiptConfirmation(ctx context.Context, cq *tgbotapi.CallbackQuery) {
+	defer b.answerCallback(cq)
+
+	pending, ok := b.receiptSessions.get(cq.From.ID)
+	if !ok {
+		return
+	}
+	b.receiptSessions.delete(cq.From.ID)
+
+	if strings.HasPrefix(cq.Data, "cancel_receipt:") {
+		b.sendMessage(pending.chatID, b.messages[pending.lang]["confirm_cancelled"])
+		return
+	}
+
+	sheetsClient, userConfig, err := b.resolveSheetsClient(ctx, pending.userID)
+	if err != nil {
+		b.sendMessage(pending.chatID, b.messages[pending.lang]["not_connected"])
+		return
+	}
+
+	sheetName := b.getSheetName(pending.lang)
+	records, err := sheetsClient.recordReceipt(ctx, userConfig, sheetName, pending.items, pending.isCard, pending.lang, b.messages, b.categoryRowCache)
+	if err != nil {
+		log.Printf("Error recording receipt: %v", err)
+		b.sendMessage(pending.chatID, fmt.Sprintf(b.messages[pending.lang]["error_occurred"], err))
+		return
+	}
+
+	now := time.Now()
+	for i, item := range pending.items {
+		if _, err := b.history.Record(Transaction{
+			UserID:        pending.userID,
+			Timestamp:     now,
+			Amount:        item.Amount,
+			Category:      item.Category,
+			IsCard:        pending.isCard,
+			SheetName:     records[i].SheetName,
+			DailyCell:     records[i].DailyCell,
+			CategoryCell:  records[i].CategoryCell,
+			FormattedTerm: records[i].FormattedTerm,
+			ReceiptFileID: pending.fileID,
+		}); err != nil {
+			log.Printf("Error recording receipt history: %v", err)
+		}
+	}
+
+	b.sendMessage(pending.chatID, fmt.Sprintf(b.messages[pending.lang]["receipt_added"], len(pending.items), pending.total))
+}
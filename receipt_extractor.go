@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// ReceiptLineItem is a single line item an extractor pulled off a receipt.
+type ReceiptLineItem struct {
+	Description string
+	Category    string
+	Amount      float64
+}
+
+// ExtractedReceipt is everything recordReceipt needs to split a scanned
+// receipt across categories.
+type ExtractedReceipt struct {
+	LineItems []ReceiptLineItem
+	Total     float64
+}
+
+// ReceiptExtractor turns a photographed receipt into structured line items,
+// the receipt-ingestion counterpart to Parser for free-text messages.
+type ReceiptExtractor interface {
+	Extract(ctx context.Context, image []byte, mimeType string) (ExtractedReceipt, error)
+}
+
+// ReceiptConfig configures which ReceiptExtractor backend receipt photos go through.
+type ReceiptConfig struct {
+	Provider string `json:"provider"` // "tesseract" (default, zero-config OCR) or "vision"
+	Model    string `json:"model"`
+	APIKey   string `json:"api_key"`
+	BaseURL  string `json:"base_url"`
+}
+
+// NewReceiptExtractor builds the ReceiptExtractor for cfg.Provider.
+func NewReceiptExtractor(cfg ReceiptConfig) (ReceiptExtractor, error) {
+	switch cfg.Provider {
+	case "", "tesseract":
+		return &tesseractExtractor{}, nil
+	case "vision":
+		return &visionExtractor{client: &openAIVisionClient{
+			apiKey:  cfg.APIKey,
+			model:   defaultString(cfg.Model, "gpt-4o"),
+			baseURL: defaultString(cfg.BaseURL, "https://api.openai.com/v1"),
+		}}, nil
+	default:
+		return nil, fmt.Errorf("unknown receipt extractor provider %q", cfg.Provider)
+	}
+}
+
+// extractReceiptSchema forces a vision-capable model to return exactly the
+// fields ExtractedReceipt needs.
+const extractReceiptSchema = `{
+	"name": "extract_receipt",
+	"description": "Extract every line item and the total from a photographed receipt",
+	"parameters": {
+		"type": "object",
+		"properties": {
+			"line_items": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {
+						"description": {"type": "string"},
+						"category": {"type": "string"},
+						"amount": {"type": "number"}
+					},
+					"required": ["description", "category", "amount"]
+				}
+			},
+			"total": {"type": "number"}
+		},
+		"required": ["line_items", "total"]
+	}
+}`
+
+// visionClient sends a receipt image to a vision-capable model and returns
+// the raw JSON arguments it produced for the extract_receipt tool.
+type visionClient interface {
+	CompleteJSON(ctx context.Context, image []byte, mimeType string) (string, error)
+}
+
+// visionExtractor delegates extraction to a vision-capable LLM.
+type visionExtractor struct {
+	client visionClient
+}
+
+func (e *visionExtractor) Extract(ctx context.Context, image []byte, mimeType string) (ExtractedReceipt, error) {
+	raw, err := e.client.CompleteJSON(ctx, image, mimeType)
+	if err != nil {
+		return ExtractedReceipt{}, fmt.Errorf("failed to call vision extractor: %w", err)
+	}
+
+	var parsed struct {
+		LineItems []struct {
+			Description string  `json:"description"`
+			Category    string  `json:"category"`
+			Amount      float64 `json:"amount"`
+		} `json:"line_items"`
+		Total float64 `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return ExtractedReceipt{}, fmt.Errorf("failed to parse vision response %q: %w", raw, err)
+	}
+
+	receipt := ExtractedReceipt{Total: parsed.Total}
+	for _, item := range parsed.LineItems {
+		receipt.LineItems = append(receipt.LineItems, ReceiptLineItem{
+			Description: item.Description,
+			Category:    item.Category,
+			Amount:      item.Amount,
+		})
+	}
+	return receipt, nil
+}
+
+// openAIVisionClient calls OpenAI's chat completions API with an inline
+// base64 image and a forced tool call — the vision counterpart of
+// openAIClient in parser_llm.go.
+type openAIVisionClient struct {
+	apiKey  string
+	model   string
+	baseURL string
+}
+
+func (c *openAIVisionClient) CompleteJSON(ctx context.Context, image []byte, mimeType string) (string, error) {
+	dataURL := fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(image))
+
+	body := map[string]interface{}{
+		"model": c.model,
+		"messages": []map[string]interface{}{
+			{
+				"role": "user",
+				"content": []map[string]interface{}{
+					{"type": "text", "text": "Extract every line item and the total from this receipt."},
+					{"type": "image_url", "image_url": map[string]string{"url": dataURL}},
+				},
+			},
+		},
+		"tools":       []json.RawMessage{[]byte(`{"type":"function","function":` + extractReceiptSchema + `}`)},
+		"tool_choice": map[string]interface{}{"type": "function", "function": map[string]string{"name": "extract_receipt"}},
+	}
+
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				ToolCalls []struct {
+					Function struct {
+						Arguments string `json:"arguments"`
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := postJSON(ctx, c.baseURL+"/chat/completions", c.apiKey, body, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 || len(resp.Choices[0].Message.ToolCalls) == 0 {
+		return "", fmt.Errorf("openai returned no tool call")
+	}
+	return resp.Choices[0].Message.ToolCalls[0].Function.Arguments, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// RecurringExpense is a schedule that automatically records the same
+// expense every time its cron spec fires.
+type RecurringExpense struct {
+	ID       int64
+	UserID   int64
+	ChatID   int64
+	Amount   float64
+	Category string
+	IsCard   bool
+	CronSpec string
+	NextRun  time.Time
+}
+
+// RecurringStore persists /recurring schedules.
+type RecurringStore struct {
+	db *sql.DB
+}
+
+func NewRecurringStore(path string) (*RecurringStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recurring store: %w", err)
+	}
+
+	const schema = `
+	CREATE TABLE IF NOT EXISTS recurring_expenses (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id    INTEGER NOT NULL,
+		chat_id    INTEGER NOT NULL,
+		amount     REAL NOT NULL,
+		category   TEXT NOT NULL,
+		is_card    INTEGER NOT NULL,
+		cron_spec  TEXT NOT NULL,
+		next_run   DATETIME NOT NULL
+	);`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to init recurring schema: %w", err)
+	}
+
+	return &RecurringStore{db: db}, nil
+}
+
+// Add inserts a new recurring schedule and returns its ID.
+func (rs *RecurringStore) Add(re RecurringExpense) (int64, error) {
+	res, err := rs.db.Exec(
+		`INSERT INTO recurring_expenses (user_id, chat_id, amount, category, is_card, cron_spec, next_run)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		re.UserID, re.ChatID, re.Amount, re.Category, re.IsCard, re.CronSpec, re.NextRun,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add recurring expense: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// Due returns every schedule whose next_run is at or before now.
+func (rs *RecurringStore) Due(now time.Time) ([]RecurringExpense, error) {
+	rows, err := rs.db.Query(
+		`SELECT id, user_id, chat_id, amount, category, is_card, cron_spec, next_run
+		 FROM recurring_expenses WHERE next_run <= ?`,
+		now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due recurring expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var due []RecurringExpense
+	for rows.Next() {
+		var re RecurringExpense
+		if err := rows.Scan(&re.ID, &re.UserID, &re.ChatID, &re.Amount, &re.Category, &re.IsCard, &re.CronSpec, &re.NextRun); err != nil {
+			return nil, fmt.Errorf("failed to scan recurring expense: %w", err)
+		}
+		due = append(due, re)
+	}
+	return due, rows.Err()
+}
+
+// UpdateNextRun moves a schedule's next fire time forward after it runs.
+func (rs *RecurringStore) UpdateNextRun(id int64, next time.Time) error {
+	_, err := rs.db.Exec(`UPDATE recurring_expenses SET next_run = ? WHERE id = ?`, next, id)
+	if err != nil {
+		return fmt.Errorf("failed to update next_run for recurring expense %d: %w", id, err)
+	}
+	return nil
+}
+
+func (rs *RecurringStore) Close() error {
+	return rs.db.Close()
+}
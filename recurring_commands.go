@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// /recurring add <amount> <card|cash> <category> <cron>
+func (b *Bot) handleRecurringCommand(message *tgbotapi.Message, lang string) {
+	fields := strings.Fields(message.CommandArguments())
+	if len(fields) < 5 || fields[0] != "add" {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["recurring_usage"])
+		return
+	}
+
+	amount, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["invalid_amount"])
+		return
+	}
+
+	isCard, err := b.parsePaymentMethod(fields[2], lang)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, err.Error())
+		return
+	}
+
+	// The cron spec is always the trailing fields (it may itself contain
+	// spaces, e.g. "0 9 * * *"), category is whatever sits between payment
+	// method and cron spec.
+	cronFields, categoryFields := splitCronSuffix(fields[3:])
+	if len(categoryFields) == 0 || cronFields == "" {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["recurring_usage"])
+		return
+	}
+	category := strings.Join(categoryFields, " ")
+
+	schedule, err := parseCronSpec(cronFields)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["invalid_cron"])
+		return
+	}
+
+	id, err := b.recurring.Add(RecurringExpense{
+		UserID:   message.From.ID,
+		ChatID:   message.Chat.ID,
+		Amount:   amount,
+		Category: category,
+		IsCard:   isCard,
+		CronSpec: cronFields,
+		NextRun:  schedule.Next(time.Now()),
+	})
+	if err != nil {
+		log.Printf("Error adding recurring expense: %v", err)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["error_occurred"], err))
+		return
+	}
+
+	b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["recurring_added"], id))
+}
+
+// splitCronSuffix recognizes a standard 5-field cron expression or an
+// @daily/@monthly/@every-style descriptor at the end of fields, returning
+// the cron spec and whatever remains as the category.
+func splitCronSuffix(fields []string) (cronSpec string, category []string) {
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	last := fields[len(fields)-1]
+	if strings.HasPrefix(last, "@") {
+		return last, fields[:len(fields)-1]
+	}
+
+	if len(fields) >= 5 {
+		return strings.Join(fields[len(fields)-5:], " "), fields[:len(fields)-5]
+	}
+
+	return "", fields
+}
+
+// /tz <IANA timezone name>, e.g. /tz Europe/Moscow
+func (b *Bot) handleTzCommand(message *tgbotapi.Message, lang string) {
+	tz := strings.TrimSpace(message.CommandArguments())
+	if tz == "" {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["tz_usage"])
+		return
+	}
+
+	if _, err := time.LoadLocation(tz); err != nil {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["invalid_timezone"])
+		return
+	}
+
+	if err := b.preferences.SetTimezone(message.From.ID, message.Chat.ID, tz); err != nil {
+		log.Printf("Error setting timezone: %v", err)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["error_occurred"], err))
+		return
+	}
+
+	b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["tz_set"], tz))
+}
+
+// /reminders <HH:MM> enables a daily budget nudge, /reminders off disables it.
+func (b *Bot) handleRemindersCommand(message *tgbotapi.Message, lang string) {
+	arg := strings.TrimSpace(message.CommandArguments())
+	if arg == "" {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["reminders_usage"])
+		return
+	}
+
+	if strings.EqualFold(arg, "off") {
+		if err := b.preferences.DisableReminder(message.From.ID); err != nil {
+			log.Printf("Error disabling reminder: %v", err)
+			b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["error_occurred"], err))
+			return
+		}
+		b.sendMessage(message.Chat.ID, b.messages[lang]["reminders_disabled"])
+		return
+	}
+
+	prefs, err := b.preferences.Get(message.From.ID)
+	if err != nil || prefs.Timezone == "" {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["tz_required"])
+		return
+	}
+
+	if _, err := time.Parse("15:04", arg); err != nil {
+		b.sendMessage(message.Chat.ID, b.messages[lang]["reminders_usage"])
+		return
+	}
+
+	if err := b.preferences.SetReminder(message.From.ID, message.Chat.ID, arg); err != nil {
+		log.Printf("Error setting reminder: %v", err)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["error_occurred"], err))
+		return
+	}
+
+	b.sendMessage(message.Chat.ID, fmt.Sprintf(b.messages[lang]["reminders_set"], arg))
+}
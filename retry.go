@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// maxRetryAttempts bounds how many times withRetry will retry a rate-limited
+// or momentarily unavailable Sheets call before giving up.
+const maxRetryAttempts = 5
+
+// ErrSheetsUnavailable wraps whatever Google returned once withRetry has
+// exhausted its attempts; callers use it to decide whether a write should
+// fall back to the offline outbox instead of failing outright.
+var ErrSheetsUnavailable = errors.New("google sheets API unavailable after retries")
+
+// withRetry runs fn, retrying with exponential backoff when Google responds
+// 429 (rate limited) or 503 (unavailable), honoring any Retry-After header
+// Google sends. Any other error is returned immediately.
+func withRetry(ctx context.Context, fn func() error) error {
+	var lastErr error
+	for attempt := 0; attempt < maxRetryAttempts; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		wait, retryable := retryDelay(err, attempt)
+		if !retryable {
+			return err
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return fmt.Errorf("%w: %v", ErrSheetsUnavailable, lastErr)
+}
+
+// retryDelay inspects err for a Google API 429/503 and returns how long to
+// wait before retrying. Google's own Retry-After header always wins over
+// our backoff schedule.
+func retryDelay(err error, attempt int) (time.Duration, bool) {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return 0, false
+	}
+	if apiErr.Code != http.StatusTooManyRequests && apiErr.Code != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	if retryAfter := apiErr.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, convErr := strconv.Atoi(retryAfter); convErr == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+
+	backoff := time.Duration(math.Pow(2, float64(attempt))) * 500 * time.Millisecond
+	jitter := time.Duration(rand.Int63n(int64(backoff/2) + 1))
+	return backoff + jitter, true
+}
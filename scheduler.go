@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// schedulerInterval is how often the background loop checks for due
+// recurring expenses and reminders. A minute matches cron's own resolution.
+const schedulerInterval = time.Minute
+
+// cronParser accepts standard 5-field specs plus the @daily/@monthly/@every
+// descriptors.
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// parseCronSpec parses a user-supplied cron spec into a Schedule.
+func parseCronSpec(spec string) (cron.Schedule, error) {
+	schedule, err := cronParser.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron spec %q: %w", spec, err)
+	}
+	return schedule, nil
+}
+
+// startScheduler runs the recurring-expense and reminder sweeps in the
+// background for as long as ctx is alive. It is started once from Bot.Start.
+func (b *Bot) startScheduler(ctx context.Context) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	// Run once immediately so schedules, reminders and queued offline
+	// writes missed while the bot was down are caught up without waiting
+	// for the first tick.
+	b.processDueRecurring(ctx)
+	b.processDueReminders(ctx)
+	b.drainOutbox(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.processDueRecurring(ctx)
+			b.processDueReminders(ctx)
+			b.drainOutbox(ctx)
+		}
+	}
+}
+
+// drainOutbox retries every queued expense. A write that still can't reach
+// Sheets is left in the outbox with its latest error for /pending to show;
+// a successful write is removed from the queue and the user is notified.
+func (b *Bot) drainOutbox(ctx context.Context) {
+	pending, err := b.outbox.All()
+	if err != nil {
+		log.Printf("Error listing outbox: %v", err)
+		return
+	}
+
+	for _, pe := range pending {
+		sheetsClient, userConfig, err := b.resolveSheetsClient(ctx, pe.UserID)
+		if err != nil {
+			continue // still not connected; retry on the next sweep
+		}
+
+		reply, err := b.writeExpense(ctx, sheetsClient, userConfig, pe.UserID, pe.Lang, pe.Amount, pe.Category, pe.IsCard)
+		if err != nil {
+			if markErr := b.outbox.MarkFailed(pe.ID, err.Error()); markErr != nil {
+				log.Printf("Error updating outbox entry %d: %v", pe.ID, markErr)
+			}
+			continue
+		}
+
+		if err := b.outbox.Remove(pe.ID); err != nil {
+			log.Printf("Error removing drained outbox entry %d: %v", pe.ID, err)
+		}
+		b.sendMessage(pe.ChatID, reply)
+	}
+}
+
+// processDueRecurring fires every recurring expense whose next_run has
+// passed. A schedule missed for multiple intervals while the bot was
+// offline fires exactly once for the catch-up, then resumes from now.
+func (b *Bot) processDueRecurring(ctx context.Context) {
+	due, err := b.recurring.Due(time.Now())
+	if err != nil {
+		log.Printf("Error listing due recurring expenses: %v", err)
+		return
+	}
+
+	for _, re := range due {
+		lang := b.getUserLanguage(re.UserID)
+		b.finalizeExpense(ctx, re.ChatID, re.UserID, lang, re.Amount, re.Category, re.IsCard)
+
+		schedule, err := parseCronSpec(re.CronSpec)
+		if err != nil {
+			log.Printf("Error re-parsing cron spec for recurring expense %d: %v", re.ID, err)
+			continue
+		}
+
+		if err := b.recurring.UpdateNextRun(re.ID, schedule.Next(time.Now())); err != nil {
+			log.Printf("Error updating next run for recurring expense %d: %v", re.ID, err)
+		}
+	}
+}
+
+// processDueReminders sends each user's daily budget nudge once their
+// configured local time has passed for the day, catching up a reminder
+// missed while the bot was offline on the next sweep.
+func (b *Bot) processDueReminders(ctx context.Context) {
+	users, err := b.preferences.ListReminderEnabled()
+	if err != nil {
+		log.Printf("Error listing reminder-enabled users: %v", err)
+		return
+	}
+
+	for _, prefs := range users {
+		if !b.reminderDue(prefs) {
+			continue
+		}
+
+		lang := b.getUserLanguage(prefs.UserID)
+		if err := b.sendBudgetReminder(ctx, prefs, lang); err != nil {
+			log.Printf("Error sending reminder to user %d: %v", prefs.UserID, err)
+			continue
+		}
+
+		today := b.userLocalDate(prefs)
+		if err := b.preferences.MarkReminded(prefs.UserID, today); err != nil {
+			log.Printf("Error marking reminder sent for user %d: %v", prefs.UserID, err)
+		}
+	}
+}
+
+// reminderDue reports whether prefs' configured local time has passed today
+// and today's reminder hasn't already been sent.
+func (b *Bot) reminderDue(prefs UserPreferences) bool {
+	loc := userLocation(prefs)
+	now := time.Now().In(loc)
+	if prefs.LastReminded == now.Format("2006-01-02") {
+		return false
+	}
+
+	reminderAt, err := time.ParseInLocation("15:04", prefs.ReminderTime, loc)
+	if err != nil {
+		return false
+	}
+
+	scheduledToday := time.Date(now.Year(), now.Month(), now.Day(), reminderAt.Hour(), reminderAt.Minute(), 0, 0, loc)
+	return !now.Before(scheduledToday)
+}
+
+// userLocalDate returns today's date in prefs' configured timezone, the
+// same "2006-01-02" format reminderDue compares LastReminded against.
+func (b *Bot) userLocalDate(prefs UserPreferences) string {
+	return time.Now().In(userLocation(prefs)).Format("2006-01-02")
+}
+
+// userLocation resolves prefs.Timezone, falling back to UTC for an unset or
+// invalid value.
+func userLocation(prefs UserPreferences) *time.Location {
+	loc, err := time.LoadLocation(prefs.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func (b *Bot) sendBudgetReminder(ctx context.Context, prefs UserPreferences, lang string) error {
+	sheetsClient, userConfig, err := b.resolveSheetsClient(ctx, prefs.UserID)
+	if err != nil {
+		return err
+	}
+
+	sheetName := b.getSheetName(lang)
+	budget, err := sheetsClient.getDailyBudget(ctx, userConfig, sheetName)
+	if err != nil {
+		return err
+	}
+
+	budgetValue, err := strconv.ParseFloat(budget, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse budget value: %w", err)
+	}
+
+	reply := fmt.Sprintf(b.messages[lang]["daily_reminder"], budgetValue)
+	b.sendMessage(prefs.ChatID, reply)
+	return nil
+}
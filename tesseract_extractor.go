@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// tesseractExtractor shells out to the tesseract CLI (zero-config, no API
+// key, no cgo binding) and recovers line items from its raw text output
+// with a regex heuristic — the receipt-ingestion counterpart of RegexParser.
+type tesseractExtractor struct{}
+
+// receiptLinePattern matches a trailing "<description> <amount>" on a line,
+// e.g. "Oat milk latte     4.50".
+var receiptLinePattern = regexp.MustCompile(`^(.+?)\s+(\d+[.,]\d{2})$`)
+
+var totalLinePattern = regexp.MustCompile(`(?i)total`)
+
+func (e *tesseractExtractor) Extract(ctx context.Context, image []byte, mimeType string) (ExtractedReceipt, error) {
+	tmp, err := os.CreateTemp("", "receipt-*"+extensionForMimeType(mimeType))
+	if err != nil {
+		return ExtractedReceipt{}, fmt.Errorf("failed to create temp receipt file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(image); err != nil {
+		tmp.Close()
+		return ExtractedReceipt{}, fmt.Errorf("failed to write temp receipt file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return ExtractedReceipt{}, fmt.Errorf("failed to close temp receipt file: %w", err)
+	}
+
+	out, err := exec.CommandContext(ctx, "tesseract", tmp.Name(), "stdout").Output()
+	if err != nil {
+		return ExtractedReceipt{}, fmt.Errorf("failed to run tesseract: %w", err)
+	}
+
+	return parseReceiptText(string(out)), nil
+}
+
+// parseReceiptText pulls "<description> <amount>" lines out of tesseract's
+// raw text, treating any line mentioning "total" as the receipt's total
+// rather than another line item.
+func parseReceiptText(text string) ExtractedReceipt {
+	var receipt ExtractedReceipt
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		match := receiptLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		amount, err := strconv.ParseFloat(strings.Replace(match[2], ",", ".", 1), 64)
+		if err != nil {
+			continue
+		}
+		description := strings.TrimSpace(match[1])
+
+		if totalLinePattern.MatchString(description) {
+			receipt.Total = amount
+			continue
+		}
+
+		receipt.LineItems = append(receipt.LineItems, ReceiptLineItem{
+			Description: description,
+			Category:    description,
+			Amount:      amount,
+		})
+	}
+
+	if receipt.Total == 0 {
+		for _, item := range receipt.LineItems {
+			receipt.Total += item.Amount
+		}
+	}
+	return receipt
+}
+
+func extensionForMimeType(mimeType string) string {
+	if mimeType == "image/png" {
+		return ".png"
+	}
+	return ".jpg"
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+)
+
+// tokenEncryptionEnvVar names the env var holding the base64-encoded AES-256
+// key used to encrypt OAuth tokens at rest, since UserStore is a plain
+// SQLite file rather than a secrets manager.
+const tokenEncryptionEnvVar = "TOKEN_ENCRYPTION_KEY"
+
+// loadTokenCipher reads the AES-GCM cipher used to encrypt/decrypt stored
+// OAuth tokens from TOKEN_ENCRYPTION_KEY (32 raw bytes, base64-encoded).
+func loadTokenCipher() (cipher.AEAD, error) {
+	encoded := os.Getenv(tokenEncryptionEnvVar)
+	if encoded == "" {
+		return nil, fmt.Errorf("%s is not set", tokenEncryptionEnvVar)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", tokenEncryptionEnvVar, err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("%s must decode to 32 bytes for AES-256, got %d", tokenEncryptionEnvVar, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptToken seals plaintext with a random nonce prepended to the ciphertext.
+func encryptToken(gcm cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// decryptToken reverses encryptToken.
+func decryptToken(gcm cipher.AEAD, sealed []byte) ([]byte, error) {
+	nonceSize := gcm.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("encrypted token is too short")
+	}
+	nonce, ciphertext := sealed[:nonceSize], sealed[nonceSize:]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
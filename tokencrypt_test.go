@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+)
+
+func testGCM(t *testing.T) cipher.AEAD {
+	t.Helper()
+	block, err := aes.NewCipher(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("failed to create AES cipher: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("failed to create GCM: %v", err)
+	}
+	return gcm
+}
+
+func TestEncryptDecryptTokenRoundTrip(t *testing.T) {
+	gcm := testGCM(t)
+	plaintext := []byte(`{"access_token":"secret","refresh_token":"also-secret"}`)
+
+	sealed, err := encryptToken(gcm, plaintext)
+	if err != nil {
+		t.Fatalf("encryptToken returned error: %v", err)
+	}
+	if string(sealed) == string(plaintext) {
+		t.Fatalf("encryptToken did not transform the plaintext")
+	}
+
+	got, err := decryptToken(gcm, sealed)
+	if err != nil {
+		t.Fatalf("decryptToken returned error: %v", err)
+	}
+	if string(got) != string(plaintext) {
+		t.Errorf("decryptToken = %q, want %q", got, plaintext)
+	}
+}
+
+func TestDecryptTokenRejectsTamperedCiphertext(t *testing.T) {
+	gcm := testGCM(t)
+	sealed, err := encryptToken(gcm, []byte("hello"))
+	if err != nil {
+		t.Fatalf("encryptToken returned error: %v", err)
+	}
+
+	sealed[len(sealed)-1] ^= 0xFF
+	if _, err := decryptToken(gcm, sealed); err == nil {
+		t.Fatal("decryptToken did not reject a tampered ciphertext")
+	}
+}
+
+func TestDecryptTokenRejectsTooShortInput(t *testing.T) {
+	gcm := testGCM(t)
+	if _, err := decryptToken(gcm, []byte("short")); err == nil {
+		t.Fatal("decryptToken did not reject input shorter than the nonce size")
+	}
+}
+
+func TestGenerateOAuthStateIsUniqueAndURLSafe(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		state, err := generateOAuthState()
+		if err != nil {
+			t.Fatalf("generateOAuthState returned error: %v", err)
+		}
+		if state == "" {
+			t.Fatal("generateOAuthState returned an empty state")
+		}
+		if seen[state] {
+			t.Fatalf("generateOAuthState produced a duplicate: %q", state)
+		}
+		seen[state] = true
+	}
+}
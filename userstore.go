@@ -0,0 +1,158 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// stateTTL is how long a /connect nonce stays valid before the user must
+// re-issue the command.
+const stateTTL = 10 * time.Minute
+
+// UserRecord is one Telegram user's link to their own Google Sheet.
+type UserRecord struct {
+	TelegramUserID int64
+	SpreadsheetID  string
+	EncryptedToken []byte
+	Language       string
+	CellRanges     *CellRanges // nil means "use the bot's default ranges"
+}
+
+// UserStore is the per-tenant counterpart of HistoryStore: it is what lets
+// multiple Telegram users each connect their own spreadsheet instead of the
+// bot being wired to a single hard-coded SpreadsheetID.
+type UserStore struct {
+	db *sql.DB
+}
+
+// NewUserStore opens (and if needed creates) the local SQLite tenant table at path.
+func NewUserStore(path string) (*UserStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open user store: %w", err)
+	}
+
+	const schema = `
+	CREATE TABLE IF NOT EXISTS users (
+		telegram_user_id INTEGER PRIMARY KEY,
+		spreadsheet_id   TEXT NOT NULL,
+		encrypted_token  BLOB NOT NULL,
+		language         TEXT NOT NULL,
+		cell_ranges      TEXT
+	);
+	CREATE TABLE IF NOT EXISTS oauth_states (
+		state            TEXT PRIMARY KEY,
+		telegram_user_id INTEGER NOT NULL,
+		spreadsheet_id   TEXT NOT NULL,
+		created_at       DATETIME NOT NULL
+	);`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to init user store schema: %w", err)
+	}
+
+	return &UserStore{db: db}, nil
+}
+
+// Get returns the stored record for telegramUserID, or an error if the user
+// has never completed /connect.
+func (us *UserStore) Get(telegramUserID int64) (*UserRecord, error) {
+	var rec UserRecord
+	var cellRangesJSON sql.NullString
+
+	row := us.db.QueryRow(
+		`SELECT telegram_user_id, spreadsheet_id, encrypted_token, language, cell_ranges FROM users WHERE telegram_user_id = ?`,
+		telegramUserID,
+	)
+	if err := row.Scan(&rec.TelegramUserID, &rec.SpreadsheetID, &rec.EncryptedToken, &rec.Language, &cellRangesJSON); err != nil {
+		return nil, fmt.Errorf("failed to get user %d: %w", telegramUserID, err)
+	}
+
+	if cellRangesJSON.Valid && cellRangesJSON.String != "" {
+		var overrides CellRanges
+		if err := json.Unmarshal([]byte(cellRangesJSON.String), &overrides); err != nil {
+			return nil, fmt.Errorf("failed to parse cell range overrides for user %d: %w", telegramUserID, err)
+		}
+		rec.CellRanges = &overrides
+	}
+
+	return &rec, nil
+}
+
+// Save upserts a user's spreadsheet connection.
+func (us *UserStore) Save(rec UserRecord) error {
+	var cellRangesJSON []byte
+	if rec.CellRanges != nil {
+		var err error
+		cellRangesJSON, err = json.Marshal(rec.CellRanges)
+		if err != nil {
+			return fmt.Errorf("failed to encode cell range overrides: %w", err)
+		}
+	}
+
+	_, err := us.db.Exec(
+		`INSERT INTO users (telegram_user_id, spreadsheet_id, encrypted_token, language, cell_ranges)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(telegram_user_id) DO UPDATE SET
+			spreadsheet_id = excluded.spreadsheet_id,
+			encrypted_token = excluded.encrypted_token,
+			language = excluded.language,
+			cell_ranges = excluded.cell_ranges`,
+		rec.TelegramUserID, rec.SpreadsheetID, rec.EncryptedToken, rec.Language, string(cellRangesJSON),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save user %d: %w", rec.TelegramUserID, err)
+	}
+	return nil
+}
+
+// SaveToken updates just the encrypted OAuth token, e.g. after a refresh.
+func (us *UserStore) SaveToken(telegramUserID int64, encryptedToken []byte) error {
+	_, err := us.db.Exec(`UPDATE users SET encrypted_token = ? WHERE telegram_user_id = ?`, encryptedToken, telegramUserID)
+	if err != nil {
+		return fmt.Errorf("failed to update token for user %d: %w", telegramUserID, err)
+	}
+	return nil
+}
+
+// PutPendingState records the nonce /connect generated so the OAuth callback
+// can later recover which user and spreadsheet it belongs to.
+func (us *UserStore) PutPendingState(state string, telegramUserID int64, spreadsheetID string) error {
+	_, err := us.db.Exec(
+		`INSERT INTO oauth_states (state, telegram_user_id, spreadsheet_id, created_at) VALUES (?, ?, ?, ?)`,
+		state, telegramUserID, spreadsheetID, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store pending OAuth state: %w", err)
+	}
+	return nil
+}
+
+// ConsumePendingState looks up and deletes a /connect nonce, failing it if
+// it is unknown or older than stateTTL.
+func (us *UserStore) ConsumePendingState(state string) (telegramUserID int64, spreadsheetID string, err error) {
+	row := us.db.QueryRow(`SELECT telegram_user_id, spreadsheet_id, created_at FROM oauth_states WHERE state = ?`, state)
+
+	var createdAt time.Time
+	if err := row.Scan(&telegramUserID, &spreadsheetID, &createdAt); err != nil {
+		return 0, "", fmt.Errorf("unknown OAuth state: %w", err)
+	}
+
+	if _, err := us.db.Exec(`DELETE FROM oauth_states WHERE state = ?`, state); err != nil {
+		return 0, "", fmt.Errorf("failed to clear OAuth state: %w", err)
+	}
+
+	if time.Since(createdAt) > stateTTL {
+		return 0, "", fmt.Errorf("OAuth state expired, please run /connect again")
+	}
+
+	return telegramUserID, spreadsheetID, nil
+}
+
+func (us *UserStore) Close() error {
+	return us.db.Close()
+}